@@ -0,0 +1,115 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrChecksum is returned when decoding input that is well-formed but fails checksum verification.
+var ErrChecksum = errors.New("uuid: checksum mismatch")
+
+const (
+	// HexAlphabet is the alphabet of the FormatCanonical, FormatHex, FormatURN, FormatBraced and FormatParenthesized formats
+	HexAlphabet = "0123456789abcdef"
+	// Base64URLAlphabet is the alphabet of Base64URLEncoder
+	Base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	// Base64StdAlphabet is the alphabet of Base64StdEncoder
+	Base64StdAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	// Z85Alphabet is the alphabet of Z85Encoder
+	Z85Alphabet = z85Alphabet
+)
+
+// ChecksumEncoding wraps another encoding, appending a Luhn mod N check character to its output,
+// where N is the size of the provided alphabet. This detects all single-character substitutions
+// and most transpositions of adjacent characters. It satisfies EncoderToString and DecoderFromString.
+//
+// Characters of the wrapped encoding's output that are not in the alphabet (e.g. dashes or braces)
+// do not contribute to the checksum. If the alphabet has no upper-case letters, upper-case input
+// is treated as its lower-case equivalent.
+type ChecksumEncoding struct {
+	enc      EncoderToString
+	dec      DecoderFromString
+	alphabet string
+	index    [256]int
+}
+
+// NewChecksumEncoding creates a ChecksumEncoding wrapping the provided encoder and decoder,
+// which are typically the same value, with check characters drawn from the provided alphabet.
+func NewChecksumEncoding(enc EncoderToString, dec DecoderFromString, alphabet string) (*ChecksumEncoding, error) {
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		return nil, fmt.Errorf("uuid.NewChecksumEncoding: alphabet length %d is out of range", len(alphabet))
+	}
+	e := &ChecksumEncoding{enc: enc, dec: dec, alphabet: alphabet}
+	for i := range e.index {
+		e.index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if e.index[alphabet[i]] >= 0 {
+			return nil, fmt.Errorf("uuid.NewChecksumEncoding: duplicate character %q in alphabet", alphabet[i])
+		}
+		e.index[alphabet[i]] = i
+	}
+	for c := 'A'; c <= 'Z'; c++ {
+		if e.index[c] >= 0 {
+			return e, nil
+		}
+	}
+	for c := 'A'; c <= 'Z'; c++ {
+		e.index[c] = e.index[c+'a'-'A']
+	}
+	return e, nil
+}
+
+// EncodeToString encodes the source with the wrapped encoder and appends the check character.
+func (e *ChecksumEncoding) EncodeToString(src []byte) string {
+	s := e.enc.EncodeToString(src)
+	return s + string(e.alphabet[e.luhn(s, 2)])
+}
+
+// DecodeString verifies and strips the check character, then decodes the remaining input with the wrapped decoder.
+// Malformed input produces the wrapped decoder's error, while well-formed input with a wrong check character
+// produces ErrChecksum.
+func (e *ChecksumEncoding) DecodeString(src string) ([]byte, error) {
+	if len(src) == 0 || e.index[src[len(src)-1]] < 0 {
+		return nil, fmt.Errorf("uuid.ChecksumEncoding.DecodeString: missing check character in %s", src)
+	}
+	out, err := e.dec.DecodeString(src[:len(src)-1])
+	if err != nil {
+		return nil, err
+	}
+	if e.luhn(src, 1) != 0 {
+		return nil, ErrChecksum
+	}
+	return out, nil
+}
+
+// luhn computes the Luhn mod N sum over the characters of s, processed right to left,
+// starting with the provided factor: 2 to generate a check character, 1 to validate.
+func (e *ChecksumEncoding) luhn(s string, factor int) int {
+	n := len(e.alphabet)
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		cp := e.index[s[i]]
+		if cp < 0 {
+			continue
+		}
+		addend := factor * cp
+		factor = 3 - factor
+		sum += addend/n + addend%n
+	}
+	return (n - sum%n) % n
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewChecksumEncoding(t *testing.T) {
+	if _, err := NewChecksumEncoding(FormatHex, FormatHex, "0"); err == nil {
+		t.Error("TestNewChecksumEncoding: should fail on short alphabet")
+	}
+	if _, err := NewChecksumEncoding(FormatHex, FormatHex, "0123456789abcdeff"); err == nil {
+		t.Error("TestNewChecksumEncoding: should fail on duplicate characters")
+	}
+}
+
+func TestChecksumEncoding(t *testing.T) {
+	// Luhn mod 10 test vector
+	dec, _ := NewChecksumEncoding(FormatHex, FormatHex, "0123456789")
+	if act := dec.luhn("7992739871", 2); act != 3 {
+		t.Errorf("TestChecksumEncoding: Luhn mod 10 got %d want %d", act, 3)
+	}
+
+	tcs := []struct {
+		name     string
+		enc      interface{}
+		alphabet string
+	}{
+		{"FormatCanonical", FormatCanonical, HexAlphabet},
+		{"FormatBraced", FormatBraced, HexAlphabet},
+		{"Base64URLEncoder", Base64URLEncoder, Base64URLAlphabet},
+		{"Base64StdEncoder", Base64StdEncoder, Base64StdAlphabet},
+		{"Z85Encoder", Z85Encoder, Z85Alphabet},
+	}
+	for _, tc := range tcs {
+		e, err := NewChecksumEncoding(tc.enc.(EncoderToString), tc.enc.(DecoderFromString), tc.alphabet)
+		if err != nil {
+			t.Errorf("TestChecksumEncoding[%s]: %s", tc.name, err)
+			continue
+		}
+		for i, etc := range encTCs {
+			uuid1, _ := NewFromString(etc.src)
+			s := uuid1.EncodeToString(e)
+			if plain := uuid1.EncodeToString(tc.enc.(EncoderToString)); s[:len(s)-1] != plain {
+				t.Errorf("TestChecksumEncoding[%s][%d]: got %s, expecting %s followed by check character", tc.name, i, s, plain)
+			}
+			uuid2, err := NewFromEncodedString(e, s)
+			if err != nil {
+				t.Errorf("TestChecksumEncoding[%s][%d]: %s", tc.name, i, err)
+			} else if uuid2.String() != etc.src {
+				t.Errorf("TestChecksumEncoding[%s][%d]: got %s want %s", tc.name, i, uuid2, etc.src)
+			}
+
+			// every single-character substitution within the alphabet must be detected
+			for pos := 0; pos < len(s); pos++ {
+				if e.index[s[pos]] < 0 {
+					continue
+				}
+				c := tc.alphabet[(e.index[s[pos]]+1)%len(tc.alphabet)]
+				typo := s[:pos] + string(c) + s[pos+1:]
+				if _, err = e.DecodeString(typo); err == nil {
+					t.Errorf("TestChecksumEncoding[%s][%d]: substitution at %d not detected in %s", tc.name, i, pos, typo)
+				}
+			}
+		}
+	}
+
+	e, _ := NewChecksumEncoding(FormatCanonical, FormatCanonical, HexAlphabet)
+	s := FormatCanonical.EncodeToString(uuid)
+	s += string(HexAlphabet[e.luhn(s, 2)])
+	if _, err := e.DecodeString(strings.ToUpper(s)); err != nil {
+		t.Error("TestChecksumEncoding: should accept upper case input for lower case alphabet:", err)
+	}
+	if _, err := e.DecodeString(s[:len(s)-1] + string(HexAlphabet[(e.index[s[len(s)-1]]+1)%16])); err != ErrChecksum {
+		t.Errorf("TestChecksumEncoding: expecting ErrChecksum, got %v", err)
+	}
+	if _, err := e.DecodeString(s[:3] + "z" + s[4:]); err == nil || err == ErrChecksum {
+		t.Errorf("TestChecksumEncoding: expecting format error, got %v", err)
+	}
+	if _, err := e.DecodeString(""); err == nil || err == ErrChecksum {
+		t.Errorf("TestChecksumEncoding: expecting format error, got %v", err)
+	}
+}
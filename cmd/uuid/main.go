@@ -0,0 +1,349 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Command uuid generates, inspects and converts UUIDs.
+
+Install it with:
+
+	go install github.com/agext/uuid/cmd/uuid@latest
+
+Usage:
+
+	uuid gen [-v 1|7] [-n count] [-node id] [-crypto] [-to encoding]
+	uuid inspect [-from encoding] [-json] [uuid ...]
+	uuid convert [-from encoding] [-to encoding] [-column n] [-delim c] [-header n] [-workers n] [-skip-errors] [uuid ...]
+
+The inspect and convert subcommands read one UUID per line from the standard input
+when no UUIDs are provided as arguments.
+
+When reading from the standard input, convert streams the lines through parallel workers,
+with bounded memory use, so it can process large files. With -column, only the UUID in
+that column of each delimited line is converted (e.g. in a CSV export), and -header copies
+the given number of leading lines, such as a CSV header, unchanged. Malformed lines
+stop the conversion, unless -skip-errors is set, in which case they are reported with their
+line number on the standard error and copied unchanged.
+
+Supported encodings: canonical, hex, urn, braced, upper, parenthesized,
+base64url, base64std, z85, ascii85, base32 and proquint.
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/agext/uuid"
+)
+
+// codec is the union of the encoder and decoder interfaces implemented by all supported encodings.
+type codec interface {
+	uuid.EncoderToString
+	uuid.DecoderFromString
+}
+
+var codecs = map[string]codec{
+	"canonical":     uuid.FormatCanonical,
+	"hex":           uuid.FormatHex,
+	"urn":           uuid.FormatURN,
+	"braced":        uuid.FormatBraced,
+	"upper":         uuid.FormatUpper,
+	"parenthesized": uuid.FormatParenthesized,
+	"base64url":     uuid.Base64URLEncoder,
+	"base64std":     uuid.Base64StdEncoder,
+	"z85":           uuid.Z85Encoder,
+	"ascii85":       uuid.Ascii85Encoder,
+	"base32":        uuid.CrockfordBase32Encoder,
+	"proquint":      uuid.ProquintEncoder,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line, returning the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "gen":
+		err = gen(args[1:], stdout, stderr)
+	case "inspect":
+		err = inspect(args[1:], stdin, stdout, stderr)
+	case "convert":
+		err = convert(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "uuid: unknown subcommand %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "uuid:", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  uuid gen [-v 1|7] [-n count] [-node id] [-crypto] [-to encoding]")
+	fmt.Fprintln(w, "  uuid inspect [-from encoding] [-json] [uuid ...]")
+	fmt.Fprintln(w, "  uuid convert [-from encoding] [-to encoding] [-column n] [-delim c] [-header n] [-workers n] [-skip-errors] [uuid ...]")
+	fmt.Fprintln(w, "Encodings:", strings.Join(codecNames(), ", "))
+}
+
+func codecNames() []string {
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupCodec(name string) (codec, error) {
+	c, ok := codecs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q (supported: %s)", name, strings.Join(codecNames(), ", "))
+	}
+	return c, nil
+}
+
+// gen generates new UUIDs.
+func gen(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	version := fs.Int("v", 1, "UUID version to generate (1 or 7)")
+	count := fs.Int("n", 1, "number of UUIDs to generate")
+	node := fs.Int64("node", -1, "30-bit node id for v1 UUIDs (default random)")
+	crypto := fs.Bool("crypto", false, "use cryptographic-quality randomness for v1 UUIDs")
+	to := fs.String("to", "canonical", "output encoding")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("gen: unexpected arguments %v", fs.Args())
+	}
+	enc, err := lookupCodec(*to)
+	if err != nil {
+		return err
+	}
+	var generate func() uuid.UUID
+	switch *version {
+	case 1:
+		generate = uuid.New
+		if *crypto {
+			generate = uuid.NewCrypto
+		}
+	case 7:
+		generate = uuid.NewV7
+	default:
+		return fmt.Errorf("gen: unsupported version %d", *version)
+	}
+	if *node >= 0 {
+		if *version != 1 {
+			return fmt.Errorf("gen: -node only applies to v1 UUIDs")
+		}
+		if *node > 0x3fffffff {
+			return fmt.Errorf("gen: node id %d does not fit in 30 bits", *node)
+		}
+		uuid.SetNodeId(uint32(*node))
+	}
+
+	w := bufio.NewWriter(stdout)
+	for i := 0; i < *count; i++ {
+		fmt.Fprintln(w, generate().EncodeToString(enc))
+	}
+	return w.Flush()
+}
+
+// inspect prints the fields of UUIDs.
+func inspect(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "canonical", "input encoding")
+	asJSON := fs.Bool("json", false, "print one JSON object per UUID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dec, err := lookupCodec(*from)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(stdout)
+	enc := json.NewEncoder(w)
+	first := true
+	err = forEachInput(fs.Args(), stdin, func(s string) error {
+		u, err := decode(dec, s)
+		if err != nil {
+			return err
+		}
+		info := u.Inspect()
+		if *asJSON {
+			return enc.Encode(info)
+		}
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+		printInfo(w, info)
+		return nil
+	})
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// printInfo prints the fields of a uuid.Info that apply to the inspected UUID, one per line.
+func printInfo(w io.Writer, info uuid.Info) {
+	fmt.Fprintf(w, "uuid:       %s\n", info.UUID)
+	fmt.Fprintf(w, "version:    %d (%s)\n", info.Version, info.VersionName)
+	fmt.Fprintf(w, "variant:    %d (%s)\n", info.Variant, info.VariantName)
+	if info.Time != nil {
+		fmt.Fprintf(w, "time:       %s\n", info.Time.Format("2006-01-02T15:04:05.0000000Z07:00"))
+	}
+	if info.ClockSequence != nil {
+		fmt.Fprintf(w, "clock seq:  %d\n", *info.ClockSequence)
+	}
+	if info.Node != "" {
+		fmt.Fprintf(w, "node:       %s\n", info.Node)
+	}
+	if info.NodeId != nil {
+		fmt.Fprintf(w, "node id:    %d (0x%08x)\n", *info.NodeId, *info.NodeId)
+	}
+	if info.RandomNode != nil {
+		fmt.Fprintf(w, "random:     %t\n", *info.RandomNode)
+	}
+	if info.Namespace != "" {
+		fmt.Fprintf(w, "namespace:  %s\n", info.Namespace)
+	}
+	for _, warning := range info.Warnings {
+		fmt.Fprintf(w, "warning:    %s\n", warning)
+	}
+}
+
+// convert re-encodes UUIDs from one encoding to another.
+func convert(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "canonical", "input encoding")
+	to := fs.String("to", "canonical", "output encoding")
+	column := fs.Int("column", 0, "1-based index of the column holding the UUID in delimited input (default whole line)")
+	delim := fs.String("delim", ",", "column delimiter")
+	header := fs.Int("header", 0, "number of leading header lines copied unchanged")
+	workers := fs.Int("workers", 0, "number of parallel workers (default number of CPUs)")
+	skipErrors := fs.Bool("skip-errors", false, "report malformed lines and copy them unchanged instead of stopping")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dec, err := lookupCodec(*from)
+	if err != nil {
+		return err
+	}
+	enc, err := lookupCodec(*to)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		w := bufio.NewWriter(stdout)
+		for _, arg := range fs.Args() {
+			u, err := decode(dec, arg)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, u.EncodeToString(enc))
+		}
+		return w.Flush()
+	}
+
+	if len(*delim) != 1 {
+		return fmt.Errorf("convert: delimiter must be a single byte")
+	}
+	tr := &uuid.Transcoder{
+		Dec:       dec,
+		Enc:       enc,
+		Column:    *column,
+		Delimiter: (*delim)[0],
+		Header:    *header,
+		Workers:   *workers,
+	}
+	if dec == uuid.FormatCanonical {
+		tr.Dec = lenientCanonical{}
+	}
+	if *skipErrors {
+		tr.OnError = func(e *uuid.LineError) error {
+			fmt.Fprintln(stderr, "uuid:", e)
+			return nil
+		}
+	}
+	return tr.Transcode(stdin, stdout)
+}
+
+// lenientCanonical decodes any of the forms accepted by uuid.NewFromString.
+type lenientCanonical struct{}
+
+func (lenientCanonical) DecodeString(s string) ([]byte, error) {
+	return uuid.NewFromString(s)
+}
+
+// decode parses a UUID; the canonical encoding also accepts any of the forms accepted by uuid.NewFromString.
+func decode(dec codec, s string) (uuid.UUID, error) {
+	if dec == uuid.FormatCanonical {
+		return uuid.NewFromString(s)
+	}
+	return uuid.NewFromEncodedString(dec, s)
+}
+
+// forEachInput calls fn for each argument or, if there are none, for each non-empty line of stdin.
+func forEachInput(args []string, stdin io.Reader, fn func(string) error) error {
+	if len(args) > 0 {
+		for _, arg := range args {
+			if err := fn(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	scanner := bufio.NewScanner(stdin)
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" {
+			continue
+		}
+		if err := fn(s); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return scanner.Err()
+}
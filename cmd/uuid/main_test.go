@@ -0,0 +1,194 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/agext/uuid"
+)
+
+const uuidString = "f254df4a-184c-1019-80a4-c61cd00a6899"
+
+func runTest(t *testing.T, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestGen(t *testing.T) {
+	code, out, errOut := runTest(t, "", "gen", "-n", "5", "-node", "12345")
+	if code != 0 {
+		t.Fatalf("TestGen: exit code %d: %s", code, errOut)
+	}
+	lines := strings.Fields(out)
+	if len(lines) != 5 {
+		t.Fatalf("TestGen: expecting 5 UUIDs, got %d", len(lines))
+	}
+	for _, l := range lines {
+		u, err := uuid.NewFromString(l)
+		if err != nil {
+			t.Fatal("TestGen:", err)
+		}
+		if u.Version() != 1 || u.NodeId() != 12345 {
+			t.Errorf("TestGen: expecting v1 with node id 12345, got %+v", u)
+		}
+	}
+
+	code, out, errOut = runTest(t, "", "gen", "-crypto", "-to", "base64url")
+	if code != 0 {
+		t.Fatalf("TestGen: exit code %d: %s", code, errOut)
+	}
+	if u, err := uuid.NewFromEncodedString(uuid.Base64URLEncoder, strings.TrimSpace(out)); err != nil || u.Version() != 1 {
+		t.Errorf("TestGen(-crypto): got %s, %v", out, err)
+	}
+
+	code, out, errOut = runTest(t, "", "gen", "-v", "7")
+	if code != 0 {
+		t.Fatalf("TestGen: exit code %d: %s", code, errOut)
+	}
+	if u, err := uuid.NewFromString(strings.TrimSpace(out)); err != nil || u.Version() != 7 {
+		t.Errorf("TestGen(-v 7): got %s, %v", out, err)
+	}
+
+	for _, args := range [][]string{
+		{"gen", "-v", "3"},
+		{"gen", "-node", "2000000000"},
+		{"gen", "-v", "7", "-node", "1"},
+		{"gen", "-to", "nonsense"},
+		{"gen", "extra"},
+	} {
+		if code, _, _ = runTest(t, "", args...); code != 1 {
+			t.Errorf("TestGen(%v): expecting exit code 1, got %d", args, code)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	code, out, errOut := runTest(t, "", "inspect", uuidString)
+	if code != 0 {
+		t.Fatalf("TestInspect: exit code %d: %s", code, errOut)
+	}
+	for _, s := range []string{"uuid:       " + uuidString, "version:    1 (time-based)", "variant:    4 (RFC 4122)", "time:       ", "clock seq:  ", "node:       c6:1c:d0:0a:68:99", "node id:    ", "random:     false"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("TestInspect: expecting output to contain %q, got:\n%s", s, out)
+		}
+	}
+
+	code, out, errOut = runTest(t, "8lTfShhMEBmApMYc0ApomQ\n\nhu8sZ8yuQkGFQ2IuhYnGKg\n", "inspect", "-from", "base64url")
+	if code != 0 {
+		t.Fatalf("TestInspect: exit code %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, uuidString) || !strings.Contains(out, "86ef2c67-ccae-4241-8543-622e8589c62a\nversion:    4 (random)") {
+		t.Errorf("TestInspect(stdin): unexpected output:\n%s", out)
+	}
+
+	code, out, errOut = runTest(t, "", "inspect", "-json", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "00000000-0000-0000-0000-000000000000")
+	if code != 0 {
+		t.Fatalf("TestInspect: exit code %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"namespace":"DNS"`) || !strings.Contains(lines[1], `"warnings":["nil UUID"]`) {
+		t.Errorf("TestInspect(-json): unexpected output:\n%s", out)
+	}
+
+	code, _, errOut = runTest(t, uuidString+"\nnonsense\n", "inspect")
+	if code != 1 || !strings.Contains(errOut, "line 2") {
+		t.Errorf("TestInspect: expecting error on line 2, got %d: %s", code, errOut)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tcs := []struct {
+		from, to string
+		in, out  string
+	}{
+		{"canonical", "base64url", uuidString, "8lTfShhMEBmApMYc0ApomQ"},
+		{"base64url", "urn", "8lTfShhMEBmApMYc0ApomQ", "urn:uuid:" + uuidString},
+		{"urn", "z85", "urn:uuid:" + uuidString, "[(jHs7!+FUFtyg8=<BDO"},
+		{"z85", "upper", "[(jHs7!+FUFtyg8=<BDO", strings.ToUpper(uuidString)},
+		{"canonical", "hex", strings.ToUpper(uuidString), "f254df4a184c101980a4c61cd00a6899"},
+	}
+	for _, tc := range tcs {
+		code, out, errOut := runTest(t, "", "convert", "-from", tc.from, "-to", tc.to, tc.in)
+		if code != 0 {
+			t.Errorf("TestConvert(%s -> %s): exit code %d: %s", tc.from, tc.to, code, errOut)
+		} else if out != tc.out+"\n" {
+			t.Errorf("TestConvert(%s -> %s): got %q want %q", tc.from, tc.to, out, tc.out)
+		}
+	}
+
+	for name := range codecs {
+		code, out, errOut := runTest(t, "", "convert", "-to", name, uuidString)
+		if code != 0 {
+			t.Fatalf("TestConvert(-> %s): exit code %d: %s", name, code, errOut)
+		}
+		code, out, errOut = runTest(t, out, "convert", "-from", name)
+		if code != 0 || out != uuidString+"\n" {
+			t.Errorf("TestConvert(%s ->): exit code %d, got %q: %s", name, code, out, errOut)
+		}
+	}
+
+	if code, _, _ := runTest(t, "", "convert", "-from", "nonsense", uuidString); code != 1 {
+		t.Errorf("TestConvert: expecting exit code 1 for unknown encoding, got %d", code)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if code, _, _ := runTest(t, ""); code != 2 {
+		t.Errorf("TestUsage: expecting exit code 2 without subcommand, got %d", code)
+	}
+	if code, _, _ := runTest(t, "", "nonsense"); code != 2 {
+		t.Errorf("TestUsage: expecting exit code 2 for unknown subcommand, got %d", code)
+	}
+	if code, out, _ := runTest(t, "", "help"); code != 0 || !strings.Contains(out, "proquint") {
+		t.Errorf("TestUsage: expecting usage, got %d: %s", code, out)
+	}
+	if code, _, _ := runTest(t, "", "gen", "-h"); code != 0 {
+		t.Errorf("TestUsage: expecting exit code 0 for -h, got %d", code)
+	}
+}
+
+func TestConvertStream(t *testing.T) {
+	in := "id,owner\r\n1,F254DF4A-184C-1019-80A4-C61CD00A6899\r\n2,oops\r\n"
+	code, out, errOut := runTest(t, in, "convert", "-to", "base64url", "-column", "2", "-header", "1", "-skip-errors", "-workers", "2")
+	if code != 0 {
+		t.Fatalf("TestConvertStream: exit code %d: %s", code, errOut)
+	}
+	if want := "id,owner\r\n1,8lTfShhMEBmApMYc0ApomQ\r\n2,oops\r\n"; out != want {
+		t.Errorf("TestConvertStream: got %q want %q", out, want)
+	}
+	if strings.Contains(errOut, "line 1:") || !strings.Contains(errOut, "line 3:") {
+		t.Errorf("TestConvertStream: expecting an error on line 3 only, got %s", errOut)
+	}
+
+	code, out, errOut = runTest(t, "id,owner\r\n1,F254DF4A-184C-1019-80A4-C61CD00A6899\r\n", "convert", "-to", "hex", "-column", "2", "-header", "1")
+	if code != 0 {
+		t.Fatalf("TestConvertStream: exit code %d: %s", code, errOut)
+	}
+	if want := "id,owner\r\n1,f254df4a184c101980a4c61cd00a6899\r\n"; out != want {
+		t.Errorf("TestConvertStream: got %q want %q", out, want)
+	}
+
+	code, _, errOut = runTest(t, in, "convert", "-column", "2")
+	if code != 1 || !strings.Contains(errOut, "line 1:") {
+		t.Errorf("TestConvertStream: expecting error on line 1, got %d: %s", code, errOut)
+	}
+	if code, _, _ = runTest(t, in, "convert", "-delim", ";;"); code != 1 {
+		t.Errorf("TestConvertStream: expecting exit code 1 for invalid delimiter, got %d", code)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import "context"
+
+// contextKey is the key of the request UUID in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context carrying the UUID of the request being processed,
+// as retrieved by FromContext and added to log records by LogHandler.
+func NewContext(parent context.Context, u UUID) context.Context {
+	return context.WithValue(parent, contextKey{}, u)
+}
+
+// FromContext returns the request UUID carried by the context, if any.
+func FromContext(ctx context.Context) (UUID, bool) {
+	u, ok := ctx.Value(contextKey{}).(UUID)
+	return u, ok
+}
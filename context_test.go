@@ -0,0 +1,32 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	if u, ok := FromContext(context.Background()); ok {
+		t.Errorf("TestContext: Expecting no UUID, got %s", u)
+	}
+	u1 := New()
+	u2, ok := FromContext(NewContext(context.Background(), u1))
+	if !ok || !bytes.Equal(u1, u2) {
+		t.Errorf("TestContext: Expecting %s, got %s", u1, u2)
+	}
+}
@@ -14,7 +14,12 @@
 
 package uuid
 
-import "encoding/base64"
+import (
+	"encoding/ascii85"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+)
 
 // Encoder implementations provide a method of encoding a UUID into a byte slice.
 type Encoder interface {
@@ -26,13 +31,58 @@ type EncoderToString interface {
 	EncodeToString([]byte) string
 }
 
+// Decoder implementations provide a method of decoding a byte slice into a UUID.
+type Decoder interface {
+	Decode([]byte) ([]byte, error)
+}
+
+// DecoderFromString implementations provide a method of decoding a string into a UUID.
+type DecoderFromString interface {
+	DecodeString(string) ([]byte, error)
+}
+
 var (
 	// Base64URLEncoder uses Base64 URL Encoding
 	Base64URLEncoder = Base64Encoder{base64.RawURLEncoding}
 	// Base64StdEncoder uses Base64 Std Encoding
 	Base64StdEncoder = Base64Encoder{base64.RawStdEncoding}
+	// Ascii85Encoder uses the Ascii85 encoding of encoding/ascii85, without delimiters
+	Ascii85Encoder = Ascii85Encoding{}
+	// Z85Encoder uses the ZeroMQ Base-85 encoding (see https://rfc.zeromq.org/spec/32/)
+	Z85Encoder = Z85Encoding{}
+	// CrockfordBase32Encoder uses Crockford's Base32 in lower case, as in the TypeID suffix
+	CrockfordBase32Encoder = CrockfordBase32Encoding{}
 )
 
+const z85Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+const crockfordAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+var (
+	z85DecodeMap       [256]byte
+	crockfordDecodeMap [256]byte
+)
+
+func init() {
+	for i := range z85DecodeMap {
+		z85DecodeMap[i] = 0xff
+		crockfordDecodeMap[i] = 0xff
+	}
+	for i := 0; i < len(z85Alphabet); i++ {
+		z85DecodeMap[z85Alphabet[i]] = byte(i)
+	}
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		c := crockfordAlphabet[i]
+		crockfordDecodeMap[c] = byte(i)
+		if c >= 'a' {
+			crockfordDecodeMap[c-'a'+'A'] = byte(i)
+		}
+	}
+	crockfordDecodeMap['o'], crockfordDecodeMap['O'] = 0, 0
+	crockfordDecodeMap['i'], crockfordDecodeMap['I'] = 1, 1
+	crockfordDecodeMap['l'], crockfordDecodeMap['L'] = 1, 1
+}
+
 // Base64Encoder is a wrapper around any encoding/base64.Encoding to satisfy Encoder and EncoderToString.
 type Base64Encoder struct {
 	Enc *base64.Encoding
@@ -49,3 +99,174 @@ func (e Base64Encoder) Encode(src []byte) (out []byte) {
 func (e Base64Encoder) EncodeToString(src []byte) (out string) {
 	return string(e.Encode(src))
 }
+
+// Decode decodes the source using the encoding/base64.Encoding set on the receiver.
+func (e Base64Encoder) Decode(src []byte) ([]byte, error) {
+	out := make([]byte, e.Enc.DecodedLen(len(src)))
+	n, err := e.Enc.Decode(out, src)
+	return out[:n], err
+}
+
+// DecodeString decodes the source string using the encoding/base64.Encoding set on the receiver.
+func (e Base64Encoder) DecodeString(src string) ([]byte, error) {
+	return e.Decode([]byte(src))
+}
+
+// Ascii85Encoding is a wrapper around encoding/ascii85 to satisfy Encoder, EncoderToString, Decoder and DecoderFromString.
+//
+// The output is not delimited with "<~" and "~>", and an all-zero 4-byte group is abbreviated to "z".
+type Ascii85Encoding struct{}
+
+// Encode encodes the source to a byte slice using Ascii85.
+func (Ascii85Encoding) Encode(src []byte) []byte {
+	out := make([]byte, ascii85.MaxEncodedLen(len(src)))
+	return out[:ascii85.Encode(out, src)]
+}
+
+// EncodeToString encodes the source to a string using Ascii85.
+func (e Ascii85Encoding) EncodeToString(src []byte) string {
+	return string(e.Encode(src))
+}
+
+// Decode decodes the Ascii85 source, ignoring any white space.
+func (Ascii85Encoding) Decode(src []byte) ([]byte, error) {
+	out := make([]byte, 4*len(src))
+	n, _, err := ascii85.Decode(out, src, true)
+	if err != nil {
+		return nil, fmt.Errorf("uuid.Ascii85Encoding.Decode: %v", err)
+	}
+	return out[:n], nil
+}
+
+// DecodeString decodes the Ascii85 source string, ignoring any white space.
+func (e Ascii85Encoding) DecodeString(src string) ([]byte, error) {
+	return e.Decode([]byte(src))
+}
+
+// Z85Encoding implements the ZeroMQ Base-85 encoding to satisfy Encoder, EncoderToString, Decoder and DecoderFromString.
+//
+// Z85 requires the input length to be a multiple of 4, which is always the case for a UUID, so no padding is needed:
+// 16 bytes encode to exactly 20 characters. Any trailing partial group is encoded the same way as in Ascii85.
+type Z85Encoding struct{}
+
+// Encode encodes the source to a byte slice using Z85.
+func (Z85Encoding) Encode(src []byte) []byte {
+	out := make([]byte, 0, (len(src)+3)/4*5)
+	var group [4]byte
+	for len(src) > 0 {
+		n := copy(group[:], src)
+		for i := n; i < 4; i++ {
+			group[i] = 0
+		}
+		src = src[n:]
+		val := binary.BigEndian.Uint32(group[:])
+		var chars [5]byte
+		for i := 4; i >= 0; i-- {
+			chars[i] = z85Alphabet[val%85]
+			val /= 85
+		}
+		out = append(out, chars[:n+1]...)
+	}
+	return out
+}
+
+// EncodeToString encodes the source to a string using Z85.
+func (e Z85Encoding) EncodeToString(src []byte) string {
+	return string(e.Encode(src))
+}
+
+// Decode decodes the Z85 source.
+func (Z85Encoding) Decode(src []byte) ([]byte, error) {
+	if len(src)%5 == 1 {
+		return nil, fmt.Errorf("uuid.Z85Encoding.Decode: invalid input length %d", len(src))
+	}
+	out := make([]byte, 0, len(src)/5*4+4)
+	for pos := 0; pos < len(src); pos += 5 {
+		n := len(src) - pos
+		if n > 5 {
+			n = 5
+		}
+		var val uint64
+		for i := 0; i < 5; i++ {
+			d := byte(84)
+			if i < n {
+				if d = z85DecodeMap[src[pos+i]]; d == 0xff {
+					return nil, fmt.Errorf("uuid.Z85Encoding.Decode: illegal character %q at offset %d", src[pos+i], pos+i)
+				}
+			}
+			val = val*85 + uint64(d)
+		}
+		if val > 0xffffffff {
+			return nil, fmt.Errorf("uuid.Z85Encoding.Decode: group overflow at offset %d", pos)
+		}
+		var group [4]byte
+		binary.BigEndian.PutUint32(group[:], uint32(val))
+		out = append(out, group[:n-1]...)
+	}
+	return out, nil
+}
+
+// DecodeString decodes the Z85 source string.
+func (e Z85Encoding) DecodeString(src string) ([]byte, error) {
+	return e.Decode([]byte(src))
+}
+
+// CrockfordBase32Encoding implements Crockford's Base32 encoding, treating the source as a single
+// big-endian number, to satisfy Encoder, EncoderToString, Decoder and DecoderFromString.
+//
+// The output uses lower case and is padded with leading zero bits, so that a UUID encodes to
+// exactly 26 characters, the first of which is always between 0 and 7, as required by TypeID.
+type CrockfordBase32Encoding struct{}
+
+// Encode encodes the source to a byte slice using Crockford's Base32.
+func (CrockfordBase32Encoding) Encode(src []byte) []byte {
+	out := make([]byte, (len(src)*8+4)/5)
+	var acc, bits uint
+	j := len(out) - 1
+	for i := len(src) - 1; i >= 0; i-- {
+		acc |= uint(src[i]) << bits
+		for bits += 8; bits >= 5; bits -= 5 {
+			out[j] = crockfordAlphabet[acc&0x1f]
+			acc >>= 5
+			j--
+		}
+	}
+	if j >= 0 {
+		out[j] = crockfordAlphabet[acc&0x1f]
+	}
+	return out
+}
+
+// EncodeToString encodes the source to a string using Crockford's Base32.
+func (e CrockfordBase32Encoding) EncodeToString(src []byte) string {
+	return string(e.Encode(src))
+}
+
+// Decode decodes the Crockford's Base32 source. Decoding is case-insensitive
+// and accepts the letters i and l as 1, and o as 0.
+func (CrockfordBase32Encoding) Decode(src []byte) ([]byte, error) {
+	out := make([]byte, len(src)*5/8)
+	var acc, bits uint
+	j := len(out) - 1
+	for i := len(src) - 1; i >= 0; i-- {
+		d := crockfordDecodeMap[src[i]]
+		if d == 0xff {
+			return nil, fmt.Errorf("uuid.CrockfordBase32Encoding.Decode: illegal character %q at offset %d", src[i], i)
+		}
+		acc |= uint(d) << bits
+		for bits += 5; bits >= 8 && j >= 0; bits -= 8 {
+			out[j] = byte(acc)
+			acc >>= 8
+			j--
+		}
+	}
+	if acc != 0 {
+		return nil, fmt.Errorf("uuid.CrockfordBase32Encoding.Decode: value overflows %d bytes", len(out))
+	}
+	return out, nil
+}
+
+// DecodeString decodes the Crockford's Base32 source string.
+func (e CrockfordBase32Encoding) DecodeString(src string) ([]byte, error) {
+	return e.Decode([]byte(src))
+}
@@ -14,24 +14,29 @@
 
 package uuid
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
 type encTC struct {
 	src  string
 	b64u string
 	b64s string
+	z85  string
+	a85  string
 }
 
 var (
 	// for the purpose of these tests UUIDs don't have to be v1
 	encTCs = []encTC{
-		{"f254df4a-184c-1019-80a4-c61cd00a6899", "8lTfShhMEBmApMYc0ApomQ", "8lTfShhMEBmApMYc0ApomQ"},
-		{"86ef2c67-ccae-4241-8543-622e8589c62a", "hu8sZ8yuQkGFQ2IuhYnGKg", "hu8sZ8yuQkGFQ2IuhYnGKg"},
-		{"04e37eeb-6881-45db-976b-ec2efbb0e475", "BON-62iBRduXa-wu-7DkdQ", "BON+62iBRduXa+wu+7DkdQ"},
-		{"db1aa9d6-9497-485d-a9aa-be6609e270a7", "2xqp1pSXSF2pqr5mCeJwpw", "2xqp1pSXSF2pqr5mCeJwpw"},
-		{"63ccdba7-b775-4348-b6d1-1694fae1a729", "Y8zbp7d1Q0i20RaU-uGnKQ", "Y8zbp7d1Q0i20RaU+uGnKQ"},
-		{"43c590f3-a400-4a7e-84cf-fe64a99841ed", "Q8WQ86QASn6Ez_5kqZhB7Q", "Q8WQ86QASn6Ez/5kqZhB7Q"},
-		{"4b9ab787-b0d0-47c4-9971-6dfc5a6d8db3", "S5q3h7DQR8SZcW38Wm2Nsw", "S5q3h7DQR8SZcW38Wm2Nsw"},
+		{"f254df4a-184c-1019-80a4-c61cd00a6899", "8lTfShhMEBmApMYc0ApomQ", "8lTfShhMEBmApMYc0ApomQ", `[(jHs7!+FUFtyg8=<BDO`, `nl4L=(ebJYJ>C1)cjFHS`},
+		{"86ef2c67-ccae-4241-8543-622e8589c62a", "hu8sZ8yuQkGFQ2IuhYnGKg", "hu8sZ8yuQkGFQ2IuhYnGKg", `HvlWa+=Tn#G*Pr4G]9/0`, `L@6[+bcX8uKgT<%Ko*f!`},
+		{"04e37eeb-6881-45db-976b-ec2efbb0e475", "BON-62iBRduXa-wu-7DkdQ", "BON+62iBRduXa+wu+7DkdQ", `1MK%%xN@RbMUU}u}(]d7`, `"QOssBRrV,QYYq?qlo.(`},
+		{"db1aa9d6-9497-485d-a9aa-be6609e270a7", "2xqp1pSXSF2pqr5mCeJwpw", "2xqp1pSXSF2pqr5mCeJwpw", `*zW6xL:s{DSJ9WA3f2{4`, `gD['BPa=pHWN*[E$0#p%`},
+		{"63ccdba7-b775-4348-b6d1-1694fae1a729", "Y8zbp7d1Q0i20RaU-uGnKQ", "Y8zbp7d1Q0i20RaU+uGnKQ", `w6AY9W@>5eW:t>P}R!t3`, `A'E]*[rk&/[a>kTqVe>$`},
+		{"43c590f3-a400-4a7e-84cf-fe64a99841ed", "Q8WQ86QASn6Ez_5kqZhB7Q", "Q8WQ86QASn6Ez/5kqZhB7Q", `l=CeUQYs2oGWoTdSHc0s`, `6cG/YU]=#9K[9X.WL-!=`},
+		{"4b9ab787-b0d0-47c4-9971-6dfc5a6d8db3", "S5q3h7DQR8SZcW38Wm2Nsw", "S5q3h7DQR8SZcW38Wm2Nsw", `opAtBU*t[UNq)9wt5xn9`, `9:E>FYg>nYR;m*A>&B8*`},
 	}
 )
 
@@ -51,5 +56,124 @@ func TestEncoders(t *testing.T) {
 		if act != tc.b64s {
 			t.Errorf("TestEncoders[%d]: Base64StdEncoder got %s want %s", i, act, tc.b64s)
 		}
+
+		act = uuid.EncodeToString(Z85Encoder)
+		if act != tc.z85 {
+			t.Errorf("TestEncoders[%d]: Z85Encoder got %s want %s", i, act, tc.z85)
+		}
+
+		act = uuid.EncodeToString(Ascii85Encoder)
+		if act != tc.a85 {
+			t.Errorf("TestEncoders[%d]: Ascii85Encoder got %s want %s", i, act, tc.a85)
+		}
+	}
+}
+
+func TestDecoders(t *testing.T) {
+	for i, tc := range encTCs {
+		for _, d := range []struct {
+			name string
+			dec  DecoderFromString
+			src  string
+		}{
+			{"Base64URLEncoder", Base64URLEncoder, tc.b64u},
+			{"Base64StdEncoder", Base64StdEncoder, tc.b64s},
+			{"Z85Encoder", Z85Encoder, tc.z85},
+			{"Ascii85Encoder", Ascii85Encoder, tc.a85},
+		} {
+			uuid, err := NewFromEncodedString(d.dec, d.src)
+			if err != nil {
+				t.Errorf("TestDecoders[%d]: %s: %s", i, d.name, err)
+				continue
+			}
+			if act := uuid.String(); act != tc.src {
+				t.Errorf("TestDecoders[%d]: %s got %s want %s", i, d.name, act, tc.src)
+			}
+		}
+	}
+
+	if _, err := NewFromEncodedString(Z85Encoder, "[(jHs7!+FUFtyg8=<BD~"); err == nil {
+		t.Error("TestDecoders: Z85Encoder should fail on illegal character")
+	}
+	if _, err := NewFromEncodedString(Z85Encoder, "[(jHs7!+FUFtyg8=<BD"); err == nil {
+		t.Error("TestDecoders: Z85Encoder should fail on short input")
+	}
+	if _, err := NewFromEncodedString(Z85Encoder, "#####"); err == nil {
+		t.Error("TestDecoders: Z85Encoder should fail on group overflow")
+	}
+	if _, err := NewFromEncodedString(Ascii85Encoder, "nl4L=(ebJYJ>C1)cjFH{"); err == nil {
+		t.Error("TestDecoders: Ascii85Encoder should fail on illegal character")
+	}
+	if _, err := NewFromEncodedString(Base64URLEncoder, "8lTfShhMEBmApMYc0Apo"); err == nil {
+		t.Error("TestDecoders: Base64URLEncoder should fail on short input")
+	}
+}
+
+func TestZ85(t *testing.T) {
+	// test vector from the Z85 specification
+	src := []byte{0x86, 0x4F, 0xD2, 0x6F, 0xB5, 0x59, 0xF7, 0x5B}
+	if act := Z85Encoder.EncodeToString(src); act != "HelloWorld" {
+		t.Errorf("TestZ85: got %s want %s", act, "HelloWorld")
+	}
+	act, err := Z85Encoder.DecodeString("HelloWorld")
+	if err != nil {
+		t.Error("TestZ85:", err)
+	}
+	if !bytes.Equal(act, src) {
+		t.Errorf("TestZ85: got % x want % x", act, src)
+	}
+	for n := 1; n < 8; n++ {
+		act, err = Z85Encoder.Decode(Z85Encoder.Encode(src[:n]))
+		if err != nil {
+			t.Errorf("TestZ85[%d]: %s", n, err)
+		}
+		if !bytes.Equal(act, src[:n]) {
+			t.Errorf("TestZ85[%d]: got % x want % x", n, act, src[:n])
+		}
+	}
+}
+
+func TestCrockfordBase32(t *testing.T) {
+	tcs := []struct {
+		src string
+		out string
+	}{
+		{"00000000-0000-0000-0000-000000000000", "00000000000000000000000000"},
+		{"00000000-0000-0000-0000-000000000020", "00000000000000000000000010"},
+		{"ffffffff-ffff-ffff-ffff-ffffffffffff", "7zzzzzzzzzzzzzzzzzzzzzzzzz"},
+		{"0110c853-1d09-52d8-d73e-1194e95b5f19", "0123456789abcdefghjkmnpqrs"},
+	}
+	for _, tc := range tcs {
+		uuid, _ := NewFromString(tc.src)
+		if act := uuid.EncodeToString(CrockfordBase32Encoder); act != tc.out {
+			t.Errorf("TestCrockfordBase32(%s): got %s want %s", tc.src, act, tc.out)
+		}
+		uuid, err := NewFromEncodedString(CrockfordBase32Encoder, tc.out)
+		if err != nil {
+			t.Errorf("TestCrockfordBase32(%s): %s", tc.out, err)
+		} else if uuid.String() != tc.src {
+			t.Errorf("TestCrockfordBase32(%s): got %s want %s", tc.out, uuid, tc.src)
+		}
+	}
+
+	uuid, err := NewFromEncodedString(CrockfordBase32Encoder, "OI23456789ABCDEFGHJKMNPQRS")
+	if err != nil {
+		t.Error("TestCrockfordBase32:", err)
+	} else if uuid.String() != "0110c853-1d09-52d8-d73e-1194e95b5f19" {
+		t.Errorf("TestCrockfordBase32: lenient decoding got %s", uuid)
+	}
+	if _, err = CrockfordBase32Encoder.DecodeString("8zzzzzzzzzzzzzzzzzzzzzzzzz"); err == nil {
+		t.Error("TestCrockfordBase32: should fail on overflow")
+	}
+	if _, err = CrockfordBase32Encoder.DecodeString("0123456789abcdefghjkmnpqru"); err == nil {
+		t.Error("TestCrockfordBase32: should fail on illegal character")
+	}
+	for n := 0; n < 8; n++ {
+		act, err := CrockfordBase32Encoder.Decode(CrockfordBase32Encoder.Encode(uuid[:n]))
+		if err != nil {
+			t.Errorf("TestCrockfordBase32[%d]: %s", n, err)
+		} else if !bytes.Equal(act, uuid[:n]) {
+			t.Errorf("TestCrockfordBase32[%d]: got % x want % x", n, act, uuid[:n])
+		}
 	}
 }
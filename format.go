@@ -0,0 +1,178 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format is a textual representation of a UUID. It satisfies EncoderToString and DecoderFromString.
+type Format int
+
+const (
+	// FormatCanonical is the dash-separated lowercase hex form: f254df4a-184c-1019-80a4-c61cd00a6899
+	FormatCanonical Format = iota
+	// FormatHex is the lowercase hex form without dashes: f254df4a184c101980a4c61cd00a6899
+	FormatHex
+	// FormatURN is the URN form defined in RFC 4122: urn:uuid:f254df4a-184c-1019-80a4-c61cd00a6899
+	FormatURN
+	// FormatBraced is the Microsoft registry form: {f254df4a-184c-1019-80a4-c61cd00a6899}
+	FormatBraced
+	// FormatUpper is the dash-separated uppercase hex form: F254DF4A-184C-1019-80A4-C61CD00A6899
+	FormatUpper
+	// FormatParenthesized is the dash-separated form enclosed in parentheses: (f254df4a-184c-1019-80a4-c61cd00a6899)
+	FormatParenthesized
+)
+
+const urnPrefix = "urn:uuid:"
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case FormatCanonical:
+		return "Canonical"
+	case FormatHex:
+		return "Hex"
+	case FormatURN:
+		return "URN"
+	case FormatBraced:
+		return "Braced"
+	case FormatUpper:
+		return "Upper"
+	case FormatParenthesized:
+		return "Parenthesized"
+	}
+	return "Format(" + strconv.Itoa(int(f)) + ")"
+}
+
+// EncodeToString formats the source using the receiver format.
+// Unknown formats fall back to FormatCanonical.
+func (f Format) EncodeToString(src []byte) string {
+	switch f {
+	case FormatHex:
+		return hex.EncodeToString(src)
+	case FormatURN:
+		return urnPrefix + canonical(src)
+	case FormatBraced:
+		return "{" + canonical(src) + "}"
+	case FormatUpper:
+		return strings.ToUpper(canonical(src))
+	case FormatParenthesized:
+		return "(" + canonical(src) + ")"
+	}
+	return canonical(src)
+}
+
+// DecodeString parses the source string, which must be in the receiver format.
+// Hex digits are accepted in either case.
+func (f Format) DecodeString(src string) ([]byte, error) {
+	s := src
+	switch f {
+	case FormatURN:
+		if len(s) < len(urnPrefix) || !strings.EqualFold(s[:len(urnPrefix)], urnPrefix) {
+			return nil, fmt.Errorf("uuid.Format.DecodeString: %s is not a valid %s UUID", src, f)
+		}
+		s = s[len(urnPrefix):]
+	case FormatBraced, FormatParenthesized:
+		lhs, rhs := byte('{'), byte('}')
+		if f == FormatParenthesized {
+			lhs, rhs = '(', ')'
+		}
+		if len(s) < 2 || s[0] != lhs || s[len(s)-1] != rhs {
+			return nil, fmt.Errorf("uuid.Format.DecodeString: %s is not a valid %s UUID", src, f)
+		}
+		s = s[1 : len(s)-1]
+	}
+	if f == FormatHex {
+		if len(s) != 32 {
+			return nil, fmt.Errorf("uuid.Format.DecodeString: %s is not a valid %s UUID", src, f)
+		}
+	} else {
+		if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return nil, fmt.Errorf("uuid.Format.DecodeString: %s is not a valid %s UUID", src, f)
+		}
+		s = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("uuid.Format.DecodeString: %v", err)
+	}
+	return b, nil
+}
+
+// Format implements the fmt.Formatter interface.
+//
+// The verbs %v and %s print the canonical form, %q prints it double-quoted,
+// %x and %X print the hex form without dashes in lower and upper case respectively.
+// The %+v verb appends the version and variant and, for time-based UUIDs, the time and node id.
+// Width and the '-' flag are honored, as is precision with %v, %s and %q, truncating the printed string
+// as for a string argument, e.g. %.8s prints the first 8 characters.
+func (u UUID) Format(f fmt.State, verb rune) {
+	if len(u) != 16 {
+		fmt.Fprintf(f, "%%!%c(uuid.UUID=% x)", verb, []byte(u))
+		return
+	}
+	var s string
+	switch verb {
+	case 'v':
+		s = u.String()
+		if f.Flag('+') {
+			s += " (version: " + strconv.Itoa(u.Version()) + ", variant: " + strconv.Itoa(u.Variant())
+			if u.Version() == 1 {
+				s += ", time: " + u.Time().Format("2006-01-02T15:04:05.0000000Z07:00") +
+					", node: " + strconv.FormatUint(uint64(u.NodeId()), 16)
+			}
+			s += ")"
+		}
+	case 's':
+		s = u.String()
+	case 'q':
+		s = u.String()
+	case 'x':
+		s = u.Hex()
+	case 'X':
+		s = strings.ToUpper(u.Hex())
+	default:
+		fmt.Fprintf(f, "%%!%c(uuid.UUID=%s)", verb, u.String())
+		return
+	}
+	if p, ok := f.Precision(); ok && p < len(s) && verb != 'x' && verb != 'X' {
+		s = s[:p]
+	}
+	if verb == 'q' {
+		s = strconv.Quote(s)
+	}
+	if w, ok := f.Width(); ok && len(s) < w {
+		pad := strings.Repeat(" ", w-len(s))
+		if f.Flag('-') {
+			s += pad
+		} else {
+			s = pad + s
+		}
+	}
+	fmt.Fprint(f, s)
+}
+
+// canonical formats the source as a dash-separated hex string.
+func canonical(src []byte) string {
+	h := hex.EncodeToString(src)
+	if len(h) != 32 {
+		return h
+	}
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
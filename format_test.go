@@ -0,0 +1,105 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatEncodeDecode(t *testing.T) {
+	tcs := []struct {
+		f   Format
+		out string
+	}{
+		{FormatCanonical, "f254df4a-184c-1019-80a4-c61cd00a6899"},
+		{FormatHex, "f254df4a184c101980a4c61cd00a6899"},
+		{FormatURN, "urn:uuid:f254df4a-184c-1019-80a4-c61cd00a6899"},
+		{FormatBraced, "{f254df4a-184c-1019-80a4-c61cd00a6899}"},
+		{FormatUpper, "F254DF4A-184C-1019-80A4-C61CD00A6899"},
+		{FormatParenthesized, "(f254df4a-184c-1019-80a4-c61cd00a6899)"},
+	}
+	uuid1, _ := NewFromBytes(uuid)
+	for _, tc := range tcs {
+		if act := uuid1.EncodeToString(tc.f); act != tc.out {
+			t.Errorf("TestFormatEncodeDecode[%s]: got %s want %s", tc.f, act, tc.out)
+		}
+		uuid2, err := NewFromEncodedString(tc.f, tc.out)
+		if err != nil {
+			t.Errorf("TestFormatEncodeDecode[%s]: %s", tc.f, err)
+		} else if uuid2.String() != uuidString {
+			t.Errorf("TestFormatEncodeDecode[%s]: got %s want %s", tc.f, uuid2, uuidString)
+		}
+		if tc.f != FormatCanonical && tc.f != FormatUpper {
+			if _, err = tc.f.DecodeString(uuidString); err == nil {
+				t.Errorf("TestFormatEncodeDecode[%s]: should fail on canonical input", tc.f)
+			}
+		}
+	}
+
+	if _, err := FormatCanonical.DecodeString("f254df4a-184c-1z19-80a4-c61cd00a6899"); err == nil {
+		t.Error("TestFormatEncodeDecode: should fail on invalid hex digit(s)")
+	}
+	if _, err := FormatCanonical.DecodeString("f254df4a184c-1019-80a4-c61cd00a6899-"); err == nil {
+		t.Error("TestFormatEncodeDecode: should fail on misplaced dashes")
+	}
+	if act := Format(42).String(); act != "Format(42)" {
+		t.Errorf("TestFormatEncodeDecode: got %s want %s", act, "Format(42)")
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	uuid1, _ := NewFromBytes(uuid)
+	tcs := []struct {
+		format string
+		out    string
+	}{
+		{"%v", uuidString},
+		{"%s", uuidString},
+		{"%q", `"` + uuidString + `"`},
+		{"%x", "f254df4a184c101980a4c61cd00a6899"},
+		{"%X", "F254DF4A184C101980A4C61CD00A6899"},
+		{"%40s|", "    " + uuidString + "|"},
+		{"%-40s|", uuidString + "    |"},
+		{"%.8s", "f254df4a"},
+		{"%.8v", "f254df4a"},
+		{"%.8q", `"f254df4a"`},
+		{"%-10.8s|", "f254df4a  |"},
+		{"%.40s", uuidString},
+		{"%.8x", "f254df4a184c101980a4c61cd00a6899"},
+		{"%d", "%!d(uuid.UUID=" + uuidString + ")"},
+	}
+	for _, tc := range tcs {
+		if act := fmt.Sprintf(tc.format, uuid1); act != tc.out {
+			t.Errorf("TestFormatter[%s]: got %s want %s", tc.format, act, tc.out)
+		}
+	}
+
+	act := fmt.Sprintf("%+v", uuid1)
+	want := uuidString + " (version: 1, variant: 4, time: " + uuid1.Time().Format("2006-01-02T15:04:05.0000000Z07:00") + ", node: " + fmt.Sprintf("%x", uuid1.NodeId()) + ")"
+	if act != want {
+		t.Errorf("TestFormatter[%%+v]: got %s want %s", act, want)
+	}
+
+	uuid1[6] = 0x40
+	if act = fmt.Sprintf("%+v", uuid1); !strings.HasSuffix(act, "(version: 4, variant: 4)") {
+		t.Errorf("TestFormatter[%%+v]: got %s for non-time-based UUID", act)
+	}
+
+	if act = fmt.Sprintf("%v", UUID(nil)); act != "%!v(uuid.UUID=)" {
+		t.Errorf("TestFormatter[nil]: got %s", act)
+	}
+}
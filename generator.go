@@ -0,0 +1,530 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	randv2 "math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// aliases to allow mocking in tests
+var randReader = rand.Reader
+
+// maxGeneratorShards is the maximum number of shards of a Generator, each owning a distinct slice of the 16-bit
+// random part of the node identifier, selected by its most significant bits.
+const maxGeneratorShards = 64
+
+// Generator generates UUIDs v1 with its own clock sequence and node identifier.
+// The package-level functions New, NewCrypto, SetNodeId and NodeId use a default Generator, see SetDefaultGenerator.
+//
+// A Generator is safe for concurrent use. To avoid contention, its clock sequence state is split into shards,
+// one per CPU (up to 64) at construction time, each using a distinct range of values for the last 16 bits of
+// the node identifier, so that UUIDs generated by different shards cannot collide.
+//
+// The zero value is ready to use, with the default configuration: it is initialized on first use,
+// panicking if the cryptographic random source fails then, as the package initialization does.
+type Generator struct {
+	// once initializes a zero-value Generator
+	once sync.Once
+	// node holds the variant and the node identifier, without the last 16 bits, or, in classic mode,
+	// the variant, clock sequence and MAC address.
+	node      atomic.Uint64
+	shards    []*generatorShard
+	shardBits uint
+	// classic is set when the node identifier is a MAC address, see GeneratorConfig.Node;
+	// it does not change after construction.
+	classic bool
+	lastTs  atomic.Int64
+
+	entropyPolicy    EntropyPolicy
+	entropyFallbacks atomic.Uint64
+	health           *HealthTest
+	observer         Observer
+}
+
+// generatorShard holds the clock sequence state of a Generator shard.
+type generatorShard struct {
+	mutex         sync.Mutex
+	randBuf       []byte
+	randBufOffset int
+	clockSeq      uint16
+	nodeRand      uint16
+	rand          randv2.Source
+	// lastClock is the last timestamp read from the clock, to detect clock regressions
+	lastClock int64
+	// avoid false sharing between shards
+	_ [64]byte
+}
+
+// GeneratorConfig holds the configuration of a Generator.
+type GeneratorConfig struct {
+	// Node provides the node identifier. By default, the node id is random, as for the package-level functions.
+	//
+	// With a 30-bit node id, the generated UUIDs have the layout described in the package documentation.
+	// With a MAC address (see NodeFromMAC), the generator works in the classic mode of RFC 4122: the node
+	// identifier is the MAC address, the variant is the RFC 4122 one, the 14-bit clock sequence is random
+	// and the timestamp is incremented as needed to never repeat (see RFC 4122 section 4.2.1.2).
+	// As RFC 4122 section 4.2.1 requires, a MAC address must be used by a single generator on the host:
+	// generators sharing one do not coordinate their timestamps and clock sequences, and may collide.
+	Node NodeProvider
+	// RandSource creates the PRNG used by New to regenerate the last 16 bits of the node identifier on clock
+	// sequence rollovers. It is called once per shard and the PRNG need not be safe for concurrent use.
+	// By default, the PRNG is ChaCha8 seeded with cryptographic-quality random values, e.g. for PCG instead:
+	//
+	//	RandSource: func() rand.Source { return rand.NewPCG(rand.Uint64(), rand.Uint64()) }
+	//
+	// For the package-level New, set the generator with this configuration as default generator (see SetDefaultGenerator).
+	RandSource func() randv2.Source
+	// EntropyPolicy selects how NewCrypto and NewCryptoE handle failures of the cryptographic random source;
+	// the default is EntropyFallback.
+	EntropyPolicy EntropyPolicy
+	// HealthTest, if set, runs continuous health tests over the bytes read from the cryptographic random source,
+	// both at construction time and for NewCrypto and NewCryptoE. A failure is handled as a random source failure,
+	// except at construction time, where it makes NewGenerator return an error. For the package-level functions,
+	// set the generator with this configuration as default generator (see SetDefaultGenerator).
+	HealthTest *HealthTest
+	// Observer, if set, is notified of the generator activity, e.g. for metrics (see ExpvarObserver).
+	// To observe the package-level functions, set the generator with this configuration as default generator
+	// (see SetDefaultGenerator).
+	Observer Observer
+}
+
+// EntropyPolicy selects how a Generator handles failures of the cryptographic random source.
+type EntropyPolicy int
+
+const (
+	// EntropyFallback falls back to the PRNG of the generator (see GeneratorConfig.RandSource) until the next refill,
+	// counting the failures (see Generator.EntropyFallbacks).
+	EntropyFallback EntropyPolicy = iota
+	// EntropyError makes NewCryptoE return the error, and NewCrypto panic.
+	EntropyError
+	// EntropyBlock retries, with an exponential backoff of up to one second, until the random source recovers.
+	EntropyBlock
+)
+
+const maxEntropyBackoff = time.Second
+
+// NewGenerator creates a Generator with the provided configuration.
+func NewGenerator(config GeneratorConfig) (*Generator, error) {
+	if config.EntropyPolicy < EntropyFallback || config.EntropyPolicy > EntropyBlock {
+		return nil, fmt.Errorf("uuid.NewGenerator: unknown entropy policy %d", config.EntropyPolicy)
+	}
+	g := &Generator{entropyPolicy: config.EntropyPolicy, health: config.HealthTest, observer: config.Observer}
+	if err := g.init(config.RandSource); err != nil {
+		return nil, fmt.Errorf("uuid.NewGenerator: %v", err)
+	}
+	if config.Node == nil {
+		return g, nil
+	}
+	node, mac, err := config.Node.Node()
+	if err != nil {
+		return nil, fmt.Errorf("uuid.NewGenerator: %v", err)
+	}
+	if !mac {
+		if node>>30 != 0 {
+			return nil, fmt.Errorf("uuid.NewGenerator: node id %x does not fit in 30 bits", node)
+		}
+		g.setNodeId(uint32(node))
+		return g, nil
+	}
+	if node>>48 != 0 || node>>40&0x01 != 0 {
+		return nil, fmt.Errorf("uuid.NewGenerator: %012x is not a unicast MAC address", node)
+	}
+	// draw a fresh 14-bit clock sequence, as the one drawn by init holds the variant bit
+	var buf [2]byte
+	if err := g.readRandom(buf[:]); err != nil {
+		return nil, fmt.Errorf("uuid.NewGenerator: %v", err)
+	}
+	g.classic = true
+	clockSeq := binary.BigEndian.Uint16(buf[:]) & 0x3fff
+	g.node.Store(1<<63 /*variant*/ | uint64(clockSeq)<<48 | node)
+
+	return g, nil
+}
+
+// init initializes the node identifier and the clock sequence state of the shards with cryptographic-quality random values.
+// If newSource is nil, the shards use ChaCha8.
+func (g *Generator) init(newSource func() randv2.Source) error {
+	shards := runtime.GOMAXPROCS(0)
+	if shards > maxGeneratorShards {
+		shards = maxGeneratorShards
+	}
+	for g.shardBits = 0; 1<<g.shardBits < shards; g.shardBits++ {
+	}
+
+	buf := make([]byte, 8)
+	if err := g.readRandom(buf); err != nil {
+		return err
+	}
+	// set the variant inside the clock sequence
+	buf[0] = uint8(buf[0]&0x1f | /*variant*/ 1<<5)
+	// set the 'local' and 'multicast' bits of the MAC replacement,
+	// to avoid conflicts with real MAC addresses.
+	buf[2] = uint8((buf[2] << 2) | 0x03)
+	clockSeqAndNode := binary.BigEndian.Uint64(buf)
+	g.node.Store(clockSeqAndNode &^ 0x1fff00000000ffff)
+
+	g.shards = make([]*generatorShard, 1<<g.shardBits)
+	for i := range g.shards {
+		shard := &generatorShard{
+			// empty until the first refill
+			randBuf:       make([]byte, randBufCap),
+			randBufOffset: randBufCap,
+			clockSeq:      uint16((clockSeqAndNode >> 48) & 0x1fff),
+			nodeRand:      uint16(clockSeqAndNode & 0xffff),
+		}
+		if i > 0 {
+			if err := g.readRandom(buf[:4]); err != nil {
+				return err
+			}
+			shard.clockSeq = binary.BigEndian.Uint16(buf) & 0x1fff
+			shard.nodeRand = binary.BigEndian.Uint16(buf[2:])
+		}
+		shard.nodeRand = g.shardNodeRand(i, shard.nodeRand)
+		if newSource != nil {
+			shard.rand = newSource()
+		} else {
+			var seed [32]byte
+			if err := g.readRandom(seed[:]); err != nil {
+				return err
+			}
+			shard.rand = randv2.NewChaCha8(seed)
+		}
+		g.shards[i] = shard
+	}
+	return nil
+}
+
+// shardNodeRand replaces the most significant bits of the random part of the node identifier with the shard index.
+func (g *Generator) shardNodeRand(i int, nodeRand uint16) uint16 {
+	if g.shardBits == 0 {
+		return nodeRand
+	}
+	return uint16(i)<<(16-g.shardBits) | nodeRand&(0xffff>>g.shardBits)
+}
+
+// ready initializes a zero-value Generator on first use, with the default configuration.
+func (g *Generator) ready() {
+	g.once.Do(func() {
+		if g.shards == nil {
+			if err := g.init(nil); err != nil {
+				panic(fmt.Sprintf("uuid.Generator: %v", err))
+			}
+		}
+	})
+}
+
+// shard returns a shard picked at random, so that concurrent callers rarely share one.
+func (g *Generator) shard() (int, *generatorShard) {
+	g.ready()
+	i := int(randv2.Uint32() & (1<<g.shardBits - 1))
+	return i, g.shards[i]
+}
+
+// readRandom fills the buffer with bytes from the cryptographic random source, running the health tests if enabled.
+func (g *Generator) readRandom(b []byte) error {
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return err
+	}
+	if g.health != nil {
+		return g.health.Test(b)
+	}
+	return nil
+}
+
+// SetNodeId sets the bits of the generator node identifier corresponding to the node id.
+// Any unsigned 32-bit integer is accepted, but only the least significant 30 bits are used.
+// An error is returned if the discarded, most significant 2 bits are non-zero,
+// or if the generator uses a MAC address, which is then left unchanged.
+func (g *Generator) SetNodeId(nodeId uint32) error {
+	if err := g.setNodeId(nodeId); err != nil {
+		return fmt.Errorf("uuid.Generator.SetNodeId: %v", err)
+	}
+	return nil
+}
+
+func (g *Generator) setNodeId(nodeId uint32) error {
+	g.ready()
+	if g.classic {
+		return fmt.Errorf("the generator uses a MAC address as node identifier")
+	}
+	// keep the variant and the 'local' and 'multicast' bits of the MAC replacement,
+	// to avoid conflicts with real MAC addresses.
+	for {
+		node := g.node.Load()
+		if g.node.CompareAndSwap(node, (node&0xffff030000000000)|
+			(uint64(((nodeId&0x3f000000)<<2)|(nodeId&0x00ffffff))<<16)) {
+			break
+		}
+	}
+	if nodeId>>30 != 0 {
+		return fmt.Errorf("discarded non-zero most significant 2 bits from nodeId %x", nodeId)
+	}
+	return nil
+}
+
+// NodeId returns the node id used by the generator.
+func (g *Generator) NodeId() uint32 {
+	g.ready()
+	nodeId := g.node.Load() >> 16
+	return uint32((nodeId & 0x00ffffff) | ((nodeId & 0xfc000000) >> 2))
+}
+
+// New creates a new UUID v1 from the current time, clock sequence and node identifier.
+func (g *Generator) New() UUID {
+	uuid := make([]byte, 16)
+	g.fill(1, func(int) []byte { return uuid })
+
+	return UUID(uuid)
+}
+
+// NewV7 creates a new UUID v7 from the current time, with 12 bits of sub-millisecond precision
+// and 62 bits of cryptographic-quality randomness.
+func (g *Generator) NewV7() UUID {
+	uuid := make([]byte, 16)
+	rand.Read(uuid[8:])
+
+	ns := timeNow().UTC().UnixNano()
+	ms := ns / 1e6
+	binary.BigEndian.PutUint32(uuid[0:4], uint32(ms>>16))
+	binary.BigEndian.PutUint16(uuid[4:6], uint16(ms&0xffff))
+	// sub-millisecond precision multiplexed with version
+	binary.BigEndian.PutUint16(uuid[6:8], uint16((ns%1e6)*0x1000/1e6)| /*version*/ 7<<12)
+	uuid[8] = uuid[8]&0x3f | /*variant*/ 0x80
+
+	if g.observer != nil {
+		g.observer.Generated(7, 1)
+	}
+	return UUID(uuid)
+}
+
+// NewCrypto creates a new UUID v1 from the current time, with cryptographic-quality random clock sequence
+// and last 16 bits of the node identifier, or only random clock sequence if the generator uses a MAC address.
+// If the random source fails, NewCrypto follows the entropy policy of the generator, panicking for EntropyError.
+func (g *Generator) NewCrypto() UUID {
+	uuid, err := g.NewCryptoE()
+	if err != nil {
+		panic(err)
+	}
+	return uuid
+}
+
+// NewCryptoE creates a new UUID v1 as NewCrypto does, returning an error if the random source fails
+// and the entropy policy of the generator is EntropyError.
+func (g *Generator) NewCryptoE() (UUID, error) {
+	_, shard := g.shard()
+	g.lock(shard)
+	if shard.randBufOffset > len(shard.randBuf)-4 {
+		if err := g.refill(shard); err != nil {
+			shard.mutex.Unlock()
+			return nil, fmt.Errorf("uuid.Generator.NewCryptoE: %v", err)
+		}
+	}
+	val := binary.BigEndian.Uint32(shard.randBuf[shard.randBufOffset : shard.randBufOffset+4])
+	shard.randBufOffset += 4
+	var ts, regression int64
+	if !g.classic {
+		ts, regression = shard.now()
+	}
+	shard.mutex.Unlock()
+
+	uuid := make([]byte, 16)
+	if g.classic {
+		ts = g.reserveTimestamps(1)
+		binary.BigEndian.PutUint64(uuid[8:], g.node.Load()&0xc000ffffffffffff|uint64(val>>16&0x3fff)<<48)
+	} else {
+		binary.BigEndian.PutUint64(uuid[8:], g.node.Load()|uint64(val>>16&0x1fff)<<48|uint64(val&0xffff))
+	}
+	putTimestamp(uuid, ts)
+
+	g.observe(1, 0, regression)
+	return UUID(uuid), nil
+}
+
+// EntropyFallbacks returns the number of times the generator fell back to its PRNG
+// because the cryptographic random source failed, with the EntropyFallback policy.
+func (g *Generator) EntropyFallbacks() uint64 {
+	return g.entropyFallbacks.Load()
+}
+
+// refill fills the random buffer of the shard with randBufCap bytes from the cryptographic random source,
+// following the entropy policy if it fails. The shard must be locked.
+func (g *Generator) refill(shard *generatorShard) error {
+	backoff := time.Millisecond
+	for {
+		_, err := io.ReadFull(randReader, shard.randBuf)
+		if err == nil && g.health != nil {
+			err = g.health.Test(shard.randBuf)
+		}
+		if err == nil {
+			break
+		}
+		if g.observer != nil {
+			g.observer.EntropyFailure(err)
+		}
+		switch g.entropyPolicy {
+		case EntropyError:
+			return err
+		case EntropyBlock:
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > maxEntropyBackoff {
+				backoff = maxEntropyBackoff
+			}
+			continue
+		}
+		g.entropyFallbacks.Add(1)
+		for i := 0; i < len(shard.randBuf); i += 8 {
+			binary.BigEndian.PutUint64(shard.randBuf[i:], shard.rand.Uint64())
+		}
+		break
+	}
+	shard.randBufOffset = 0
+	return nil
+}
+
+// NewBatch creates n new UUIDs v1, backed by a single buffer, as Fill does.
+func (g *Generator) NewBatch(n int) []UUID {
+	if n <= 0 {
+		return nil
+	}
+	buf := make([]byte, 16*n)
+	g.fill(n, func(i int) []byte { return buf[16*i : 16*i+16] })
+	uuids := make([]UUID, n)
+	for i := range uuids {
+		uuids[i] = UUID(buf[16*i : 16*i+16 : 16*i+16])
+	}
+	return uuids
+}
+
+// Fill fills dst with len(dst)/16 new UUIDs v1, as New does, leaving any remaining bytes unchanged.
+// The clock sequence values are reserved under a single lock, so the UUIDs are strictly ordered
+// by Timestamp, then ClockSequence: on a clock sequence rollover, the timestamp is incremented.
+func (g *Generator) Fill(dst []byte) {
+	g.fill(len(dst)/16, func(i int) []byte { return dst[16*i : 16*i+16] })
+}
+
+// FillArray fills dst with new UUIDs v1, as Fill does.
+func (g *Generator) FillArray(dst [][16]byte) {
+	g.fill(len(dst), func(i int) []byte { return dst[i][:] })
+}
+
+// fill writes n new UUIDs v1 to the 16-byte slices returned by at.
+func (g *Generator) fill(n int, at func(i int) []byte) {
+	if n <= 0 {
+		return
+	}
+
+	if g.classic {
+		ts := g.reserveTimestamps(n)
+		clockSeqAndNode := g.node.Load()
+		for i := 0; i < n; i++ {
+			uuid := at(i)
+			binary.BigEndian.PutUint64(uuid[8:], clockSeqAndNode)
+			putTimestamp(uuid, ts+int64(i))
+		}
+		g.observe(n, 0, 0)
+		return
+	}
+
+	// after the initialization of a zero-value Generator by shard
+	s, shard := g.shard()
+	node := g.node.Load()
+	rollovers := 0
+	g.lock(shard)
+	ts, regression := shard.now()
+	for i := 0; i < n; i++ {
+		if shard.clockSeq = (shard.clockSeq + 1) & 0x1fff; shard.clockSeq == 0 {
+			shard.nodeRand = g.shardNodeRand(s, uint16(shard.rand.Uint64()))
+			rollovers++
+			if i > 0 {
+				ts++
+			}
+		}
+		uuid := at(i)
+		binary.BigEndian.PutUint64(uuid[8:], node|uint64(shard.clockSeq)<<48|uint64(shard.nodeRand))
+		putTimestamp(uuid, ts)
+	}
+	shard.mutex.Unlock()
+
+	g.observe(n, rollovers, regression)
+}
+
+// lock locks the shard, measuring the wait if it is contended and the generator is observed.
+func (g *Generator) lock(shard *generatorShard) {
+	if g.observer == nil {
+		shard.mutex.Lock()
+		return
+	}
+	if shard.mutex.TryLock() {
+		return
+	}
+	start := time.Now()
+	shard.mutex.Lock()
+	g.observer.LockWait(time.Since(start))
+}
+
+// now returns the current timestamp and, if the clock went backwards since the previous call,
+// the regression in 100-nanosecond intervals. The shard must be locked.
+func (shard *generatorShard) now() (ts int64, regression int64) {
+	ts = fromUnixNano(int64(timeNow().UTC().UnixNano()))
+	if ts < shard.lastClock {
+		regression = shard.lastClock - ts
+	}
+	shard.lastClock = ts
+	return ts, regression
+}
+
+// observe notifies the observer, if any, of n UUIDs v1 generated, with rollovers and a clock regression.
+func (g *Generator) observe(n, rollovers int, regression int64) {
+	if g.observer == nil {
+		return
+	}
+	for i := 0; i < rollovers; i++ {
+		g.observer.Rollover()
+	}
+	if regression > 0 {
+		g.observer.ClockRegression(time.Duration(regression * 100))
+	}
+	g.observer.Generated(1, n)
+}
+
+// reserveTimestamps reserves n consecutive timestamps, greater than any previously reserved one,
+// for the classic mode, returning the first one.
+func (g *Generator) reserveTimestamps(n int) int64 {
+	ts := fromUnixNano(int64(timeNow().UTC().UnixNano()))
+	for {
+		last := g.lastTs.Load()
+		if ts <= last {
+			ts = last + 1
+		}
+		if g.lastTs.CompareAndSwap(last, ts+int64(n)-1) {
+			return ts
+		}
+	}
+}
+
+// putTimestamp sets the timestamp, multiplexed with version 1, in the first 8 bytes of the UUID.
+func putTimestamp(uuid []byte, ts int64) {
+	binary.BigEndian.PutUint32(uuid[0:4], uint32(ts&0xffffffff))
+	binary.BigEndian.PutUint16(uuid[4:6], uint16((ts>>32)&0xffff))
+	binary.BigEndian.PutUint16(uuid[6:8], uint16((ts>>48)&0x0fff)| /*version*/ 1<<12)
+}
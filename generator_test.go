@@ -0,0 +1,508 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	randv2 "math/rand/v2"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewGenerator(t *testing.T) {
+	g, err := NewGenerator(GeneratorConfig{})
+	if err != nil {
+		t.Fatal("TestNewGenerator:", err)
+	}
+	if u := g.New(); u.Version() != 1 || u.Variant() != 1 || u[10]&0x03 != 0x03 {
+		t.Errorf("TestNewGenerator: unexpected layout of %s", u)
+	}
+
+	g, err = NewGenerator(GeneratorConfig{Node: NodeFromId(0x2abcdef1)})
+	if err != nil {
+		t.Fatal("TestNewGenerator:", err)
+	}
+	if act := g.NodeId(); act != 0x2abcdef1 {
+		t.Errorf("TestNewGenerator: Expecting node id %x, got %x", 0x2abcdef1, act)
+	}
+	for _, u := range []UUID{g.New(), g.NewCrypto()} {
+		if act := u.NodeId(); act != 0x2abcdef1 {
+			t.Errorf("TestNewGenerator: Expecting node id %x in %s, got %x", 0x2abcdef1, u, act)
+		}
+	}
+	if err = g.SetNodeId(42); err != nil || g.NodeId() != 42 {
+		t.Errorf("TestNewGenerator: SetNodeId(42) returned %v, node id %x", err, g.NodeId())
+	}
+
+	for _, p := range []NodeProvider{
+		NodeFromId(0x40000000),
+		nodeProviderFunc(func() (uint64, bool, error) { return 0, false, fmt.Errorf("failed") }),
+		nodeProviderFunc(func() (uint64, bool, error) { return 0x010203040506, true, nil }),
+		nodeProviderFunc(func() (uint64, bool, error) { return 0x01020304050607, true, nil }),
+	} {
+		if _, err = NewGenerator(GeneratorConfig{Node: p}); err == nil {
+			t.Error("TestNewGenerator: expecting error, got nil")
+		}
+	}
+}
+
+func TestGeneratorZeroValue(t *testing.T) {
+	// valid checks that the UUID has the variant and the 'local' and 'multicast' bits of this package
+	valid := func(u UUID) bool {
+		return u.Version() == 1 && u.Variant() == 1 && u[10]&0x03 == 0x03
+	}
+	first := map[string]func(g *Generator) UUID{
+		"New":       func(g *Generator) UUID { return g.New() },
+		"NewCrypto": func(g *Generator) UUID { return g.NewCrypto() },
+		"NewBatch":  func(g *Generator) UUID { return g.NewBatch(1)[0] },
+		"Fill": func(g *Generator) UUID {
+			b := make([]byte, 16)
+			g.Fill(b)
+			return UUID(b)
+		},
+		"FillArray": func(g *Generator) UUID {
+			a := make([][16]byte, 1)
+			g.FillArray(a)
+			return UUID(a[0][:])
+		},
+	}
+	for name, f := range first {
+		var g Generator
+		if u := f(&g); !valid(u) {
+			t.Errorf("TestGeneratorZeroValue(%s first): Expecting v1 UUID of this package, got %s", name, u)
+		}
+	}
+
+	var g Generator
+	if err := g.SetNodeId(0x1234); err != nil || g.NodeId() != 0x1234 {
+		t.Errorf("TestGeneratorZeroValue: SetNodeId(0x1234) returned %v, node id %x", err, g.NodeId())
+	}
+	for _, u := range append([]UUID{g.New(), g.NewCrypto()}, g.NewBatch(2)...) {
+		if !valid(u) || u.NodeId() != 0x1234 {
+			t.Errorf("TestGeneratorZeroValue: Expecting v1 UUID with node id 1234, got %s", u)
+		}
+	}
+
+	// concurrent first use
+	var g2 Generator
+	uuids := make([]UUID, 4)
+	var wg sync.WaitGroup
+	for i := range uuids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			uuids[i] = g2.New()
+		}(i)
+	}
+	wg.Wait()
+	for _, u := range uuids {
+		if !valid(u) || u.NodeId() != uuids[0].NodeId() {
+			t.Errorf("TestGeneratorZeroValue(concurrent): Expecting v1 UUIDs of this package with node id %x, got %s", uuids[0].NodeId(), u)
+		}
+	}
+}
+
+func TestGeneratorClassic(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() { timeNow = time.Now }()
+
+	mac := uint64(0x0a1b2c3d4e5f)
+	g, err := NewGenerator(GeneratorConfig{
+		Node: nodeProviderFunc(func() (uint64, bool, error) { return mac, true, nil }),
+	})
+	if err != nil {
+		t.Fatal("TestGeneratorClassic:", err)
+	}
+	if err = g.SetNodeId(1); err == nil {
+		t.Error("TestGeneratorClassic: SetNodeId expecting error, got nil")
+	}
+
+	seen := make(map[string]bool)
+	var last uint64
+	for i := 0; i < 1000; i++ {
+		u := g.New()
+		if i%2 == 1 {
+			u = g.NewCrypto()
+		}
+		if u.Version() != 1 || u.Variant()>>1 != 2 {
+			t.Fatalf("TestGeneratorClassic: Expecting RFC 4122 v1 UUID, got %s", u)
+		}
+		if u.Node() != mac {
+			t.Fatalf("TestGeneratorClassic: Expecting node %012x, got %012x", mac, u.Node())
+		}
+		if u.Timestamp() <= last {
+			t.Fatalf("TestGeneratorClassic: timestamp %x is not greater than %x", u.Timestamp(), last)
+		}
+		last = u.Timestamp()
+		if seen[u.String()] {
+			t.Fatalf("TestGeneratorClassic: duplicate %s", u)
+		}
+		seen[u.String()] = true
+	}
+}
+
+func TestGeneratorClassicClockSequence(t *testing.T) {
+	// the 14 bits of the clock sequence are random, including the most significant one
+	var bits [2]int
+	for i := 0; i < 64; i++ {
+		g, err := NewGenerator(GeneratorConfig{
+			Node: nodeProviderFunc(func() (uint64, bool, error) { return 0x0a1b2c3d4e5f, true, nil }),
+		})
+		if err != nil {
+			t.Fatal("TestGeneratorClassicClockSequence:", err)
+		}
+		bits[g.New().ClockSequence()>>13]++
+	}
+	if bits[0] == 0 || bits[1] == 0 {
+		t.Errorf("TestGeneratorClassicClockSequence: Expecting both values of the clock sequence bit 13, got %v", bits)
+	}
+}
+
+func TestGeneratorSharded(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	g, err := NewGenerator(GeneratorConfig{Node: NodeFromId(0x1234)})
+	if err != nil {
+		t.Fatal("TestGeneratorSharded:", err)
+	}
+	if len(g.shards) != 8 {
+		t.Fatalf("TestGeneratorSharded: Expecting 8 shards, got %d", len(g.shards))
+	}
+
+	const goroutines, count = 8, 0x4000
+	results := make([][]UUID, goroutines)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				results[i] = append(results[i], g.New())
+			}
+		}(i)
+	}
+	// clashing node ids must still not produce duplicates
+	wg.Wait()
+	seen := make(map[string]bool)
+	for _, uuids := range results {
+		for _, u := range uuids {
+			if u.NodeId() != 0x1234 || u.Variant() != 1 || u[10]&0x03 != 0x03 {
+				t.Fatalf("TestGeneratorSharded: unexpected layout of %s", u)
+			}
+			if seen[string(u)] {
+				t.Fatalf("TestGeneratorSharded: duplicate %s", u)
+			}
+			seen[string(u)] = true
+		}
+	}
+}
+
+func benchmarkParallel(b *testing.B, generate func() UUID) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			generate()
+		}
+	})
+}
+
+func BenchmarkNewParallel(b *testing.B) {
+	benchmarkParallel(b, New)
+}
+
+func BenchmarkNewCryptoParallel(b *testing.B) {
+	benchmarkParallel(b, NewCrypto)
+}
+
+func BenchmarkNewSingleShardParallel(b *testing.B) {
+	// a generator created with a single CPU has a single shard, contended like a global lock
+	procs := runtime.GOMAXPROCS(1)
+	g, err := NewGenerator(GeneratorConfig{})
+	runtime.GOMAXPROCS(procs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkParallel(b, g.New)
+}
+
+func BenchmarkNewClassicParallel(b *testing.B) {
+	g, err := NewGenerator(GeneratorConfig{
+		Node: nodeProviderFunc(func() (uint64, bool, error) { return 0x0a1b2c3d4e5f, true, nil }),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkParallel(b, g.New)
+}
+
+func TestNewBatch(t *testing.T) {
+	mac := uint64(0x0a1b2c3d4e5f)
+	classic, err := NewGenerator(GeneratorConfig{
+		Node: nodeProviderFunc(func() (uint64, bool, error) { return mac, true, nil }),
+	})
+	if err != nil {
+		t.Fatal("TestNewBatch:", err)
+	}
+	nodeId := NodeId()
+	t.Cleanup(func() { SetNodeId(nodeId) })
+	SetNodeId(0x2345)
+
+	for _, tc := range []struct {
+		name     string
+		newBatch func(int) []UUID
+	}{
+		{"NewBatch", NewBatch},
+		{"Fill", func(n int) []UUID {
+			buf := make([]byte, 16*n+5)
+			buf[16*n] = 0xaa
+			Fill(buf)
+			if buf[16*n] != 0xaa {
+				t.Error("TestNewBatch(Fill): trailing bytes overwritten")
+			}
+			uuids := make([]UUID, n)
+			for i := range uuids {
+				uuids[i] = UUID(buf[16*i : 16*i+16])
+			}
+			return uuids
+		}},
+		{"FillArray", func(n int) []UUID {
+			arr := make([][16]byte, n)
+			FillArray(arr)
+			uuids := make([]UUID, n)
+			for i := range uuids {
+				uuids[i] = UUID(arr[i][:])
+			}
+			return uuids
+		}},
+		{"classic", classic.NewBatch},
+	} {
+		if uuids := tc.newBatch(0); len(uuids) != 0 {
+			t.Errorf("TestNewBatch(%s, 0): Expecting no UUIDs, got %d", tc.name, len(uuids))
+		}
+		// crosses a clock sequence rollover
+		uuids := tc.newBatch(0x3000)
+		if len(uuids) != 0x3000 {
+			t.Fatalf("TestNewBatch(%s): Expecting %d UUIDs, got %d", tc.name, 0x3000, len(uuids))
+		}
+		seen := make(map[string]bool)
+		for i, u := range uuids {
+			if u.Version() != 1 {
+				t.Fatalf("TestNewBatch(%s): Expecting v1 UUID, got %s", tc.name, u)
+			}
+			if tc.name == "classic" && u.Node() != mac || tc.name != "classic" && u.NodeId() != 0x2345 {
+				t.Fatalf("TestNewBatch(%s): unexpected node in %s", tc.name, u)
+			}
+			if seen[string(u)] {
+				t.Fatalf("TestNewBatch(%s): duplicate %s", tc.name, u)
+			}
+			seen[string(u)] = true
+			if i == 0 {
+				continue
+			}
+			prev := uuids[i-1]
+			if u.Timestamp() < prev.Timestamp() ||
+				u.Timestamp() == prev.Timestamp() && u.ClockSequence() <= prev.ClockSequence() {
+				t.Fatalf("TestNewBatch(%s): %s is not ordered after %s", tc.name, u, prev)
+			}
+		}
+	}
+}
+
+func BenchmarkNewLoop(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New()
+	}
+}
+
+func BenchmarkFill(b *testing.B) {
+	b.ReportAllocs()
+	buf := make([]byte, 16*1024)
+	for i := 0; i < b.N; i += 1024 {
+		Fill(buf)
+	}
+}
+
+// countingSource is a rand.Source returning a fixed value and counting the calls.
+type countingSource struct {
+	value uint64
+	calls int
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.calls++
+	return s.value
+}
+
+func TestGeneratorRandSource(t *testing.T) {
+	source := &countingSource{value: 0xabcd}
+	procs := runtime.GOMAXPROCS(1)
+	g, err := NewGenerator(GeneratorConfig{RandSource: func() randv2.Source { return source }})
+	runtime.GOMAXPROCS(procs)
+	if err != nil {
+		t.Fatal("TestGeneratorRandSource:", err)
+	}
+	// rolls the 13-bit clock sequence over twice
+	for i := 0; i < 0x4000; i++ {
+		g.New()
+	}
+	if source.calls != 2 {
+		t.Errorf("TestGeneratorRandSource: Expecting 2 calls, got %d", source.calls)
+	}
+	if u := g.New(); binary.BigEndian.Uint16(u[14:]) != 0xabcd {
+		t.Errorf("TestGeneratorRandSource: Expecting node ending in abcd, got %s", u)
+	}
+
+	// package-level New, with the default generator
+	g.shards[0].clockSeq = 0x1fff
+	setDefaultGenerator(t, g)
+	if u := New(); source.calls != 3 || binary.BigEndian.Uint16(u[14:]) != 0xabcd {
+		t.Errorf("TestGeneratorRandSource(default generator): Expecting 3 calls and node ending in abcd, got %d and %s", source.calls, u)
+	}
+}
+
+// flakyReader fails the first failures reads, then reads from crypto/rand, recording the read sizes.
+type flakyReader struct {
+	mutex    sync.Mutex
+	failures int
+	reads    []int
+}
+
+func (r *flakyReader) Read(b []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reads = append(r.reads, len(b))
+	if r.failures != 0 {
+		r.failures--
+		return 0, fmt.Errorf("entropy source failure")
+	}
+	return rand.Read(b)
+}
+
+func TestNewCryptoE(t *testing.T) {
+	defer func() { randReader = rand.Reader }()
+
+	reader := &flakyReader{}
+	randReader = reader
+	g, err := NewGenerator(GeneratorConfig{EntropyPolicy: EntropyError})
+	if err != nil {
+		t.Fatal("TestNewCryptoE:", err)
+	}
+	// ignore the reads at construction time
+	reader.reads = nil
+	for i := 0; i < randBufCap/4*len(g.shards)+1; i++ {
+		if _, err = g.NewCryptoE(); err != nil {
+			t.Fatal("TestNewCryptoE:", err)
+		}
+	}
+	// one refill of randBufCap bytes per 64 UUIDs
+	for _, n := range reader.reads {
+		if n != randBufCap {
+			t.Fatalf("TestNewCryptoE: Expecting reads of %d bytes, got %v", randBufCap, reader.reads)
+		}
+	}
+	if len(reader.reads) > 2*len(g.shards)+1 {
+		t.Errorf("TestNewCryptoE: Expecting at most %d reads, got %d", 2*len(g.shards)+1, len(reader.reads))
+	}
+
+	// EntropyError
+	randReader = &flakyReader{failures: -1}
+	for _, shard := range g.shards {
+		shard.randBufOffset = randBufCap
+	}
+	if u, err := g.NewCryptoE(); err == nil {
+		t.Errorf("TestNewCryptoE(EntropyError): expecting error, got %s", u)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("TestNewCryptoE(EntropyError): expecting NewCrypto to panic")
+			}
+		}()
+		g.NewCrypto()
+	}()
+
+	// EntropyFallback
+	randReader = rand.Reader
+	g, _ = NewGenerator(GeneratorConfig{})
+	randReader = &flakyReader{failures: -1}
+	for i := 0; i < 3; i++ {
+		u, err := g.NewCryptoE()
+		if err != nil || u.Version() != 1 || u.Variant() != 1 {
+			t.Errorf("TestNewCryptoE(EntropyFallback): Expecting v1 UUID, got %s, %v", u, err)
+		}
+	}
+	// one per refilled shard
+	if act := g.EntropyFallbacks(); act < 1 || act > uint64(len(g.shards)) {
+		t.Errorf("TestNewCryptoE(EntropyFallback): Expecting 1 to %d fallbacks, got %d", len(g.shards), act)
+	}
+
+	// EntropyBlock
+	randReader = rand.Reader
+	g, _ = NewGenerator(GeneratorConfig{EntropyPolicy: EntropyBlock})
+	reader = &flakyReader{failures: 3}
+	randReader = reader
+	if _, err = g.NewCryptoE(); err != nil {
+		t.Error("TestNewCryptoE(EntropyBlock):", err)
+	}
+	if len(reader.reads) != 4 {
+		t.Errorf("TestNewCryptoE(EntropyBlock): Expecting 4 reads, got %d", len(reader.reads))
+	}
+
+	if _, err = NewGenerator(GeneratorConfig{EntropyPolicy: EntropyBlock + 1}); err == nil {
+		t.Error("TestNewCryptoE(unknown policy): expecting error, got nil")
+	}
+
+	// package-level, with the default generator
+	randReader = rand.Reader
+	g, _ = NewGenerator(GeneratorConfig{EntropyPolicy: EntropyError})
+	setDefaultGenerator(t, g)
+	randReader = &flakyReader{failures: -1}
+	for _, shard := range g.shards {
+		shard.randBufOffset = randBufCap
+	}
+	if u, err := NewCryptoE(); err == nil {
+		t.Errorf("TestNewCryptoE(default generator): expecting error, got %s", u)
+	}
+}
+
+// setDefaultGenerator replaces the default generator for the duration of the test.
+func setDefaultGenerator(t testing.TB, g *Generator) {
+	previous := DefaultGenerator()
+	if err := SetDefaultGenerator(g); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetDefaultGenerator(previous) })
+}
+
+func TestSetDefaultGenerator(t *testing.T) {
+	g, _ := NewGenerator(GeneratorConfig{Node: NodeFromId(0x1234)})
+	setDefaultGenerator(t, g)
+	if DefaultGenerator() != g {
+		t.Error("TestSetDefaultGenerator: Expecting DefaultGenerator to return the generator")
+	}
+	if u := New(); u.NodeId() != 0x1234 {
+		t.Errorf("TestSetDefaultGenerator: Expecting node id 1234, got %x", u.NodeId())
+	}
+	if err := SetDefaultGenerator(nil); err == nil || DefaultGenerator() != g {
+		t.Error("TestSetDefaultGenerator(nil): Expecting error and unchanged default generator")
+	}
+}
@@ -1 +1,3 @@
 module github.com/agext/uuid
+
+go 1.22
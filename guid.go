@@ -0,0 +1,74 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import "fmt"
+
+// Microsoft GUIDs (Windows APIs, .NET Guid.ToByteArray(), SQL Server uniqueidentifier) store
+// the first three fields (time_low, time_mid and time_hi_and_version) in little-endian byte order,
+// and the remaining 8 bytes in the same order as RFC 4122.
+
+// guidOrder maps each position in the GUID byte representation to the corresponding UUID position.
+var guidOrder = [16]int{3, 2, 1, 0, 5, 4, 7, 6, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// sqlServerOrder lists the UUID positions in the order of significance used by SQL Server
+// when comparing uniqueidentifier values: node first, then clock sequence, then the
+// byte-swapped time fields.
+var sqlServerOrder = [16]int{10, 11, 12, 13, 14, 15, 8, 9, 7, 6, 5, 4, 3, 2, 1, 0}
+
+// NewFromGUIDBytes creates a UUID from a slice of byte in Microsoft GUID mixed-endian byte order.
+func NewFromGUIDBytes(b []byte) (UUID, error) {
+	if len(b) != 16 {
+		return nil, fmt.Errorf("uuid.NewFromGUIDBytes: Input length is wrong (%d instead of 16)", len(b))
+	}
+	uuid := make([]byte, 16)
+	for i, j := range guidOrder {
+		uuid[j] = b[i]
+	}
+
+	return UUID(uuid), nil
+}
+
+// GUIDBytes returns the receiver UUID as a slice of byte in Microsoft GUID mixed-endian byte order,
+// as expected by .NET `new Guid(byte[])` or a SQL Server uniqueidentifier.
+func (u UUID) GUIDBytes() []byte {
+	b := make([]byte, 16)
+	for i, j := range guidOrder {
+		b[i] = u[j]
+	}
+	return b
+}
+
+// CompareSQLServer compares two UUIDs using the same ordering as SQL Server applies to
+// uniqueidentifier values, returning -1, 0 or +1 if a sorts before, the same as, or after b.
+// This is useful to predict index order or to merge results sorted by the database.
+func CompareSQLServer(a, b UUID) int {
+	for _, i := range sqlServerOrder {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// SQLServerOrder attaches the methods of sort.Interface to []UUID, sorting in SQL Server uniqueidentifier order.
+type SQLServerOrder []UUID
+
+func (s SQLServerOrder) Len() int           { return len(s) }
+func (s SQLServerOrder) Less(i, j int) bool { return CompareSQLServer(s[i], s[j]) < 0 }
+func (s SQLServerOrder) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,75 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+// .NET: new Guid("f254df4a-184c-1019-80a4-c61cd00a6899").ToByteArray()
+var guidBytes = []byte{0x4a, 0xdf, 0x54, 0xf2, 0x4c, 0x18, 0x19, 0x10, 0x80, 0xa4, 0xc6, 0x1c, 0xd0, 0x0a, 0x68, 0x99}
+
+func TestGUIDBytes(t *testing.T) {
+	uuid1, err := NewFromGUIDBytes(guidBytes)
+	if err != nil {
+		t.Error("TestGUIDBytes:", err)
+	}
+	if uuid1.String() != uuidString {
+		t.Errorf("TestGUIDBytes: Expecting %s, got %s", uuidString, uuid1)
+	}
+	if act := uuid1.GUIDBytes(); !bytes.Equal(act, guidBytes) {
+		t.Errorf("TestGUIDBytes: Expecting % x, got % x", guidBytes, act)
+	}
+	if _, err = NewFromGUIDBytes(guidBytes[1:]); err == nil {
+		t.Error("TestGUIDBytes(too short): expecting error, got nil")
+	}
+}
+
+func TestCompareSQLServer(t *testing.T) {
+	// ordered as returned by SQL Server for ORDER BY on a uniqueidentifier column
+	ordered := []string{
+		"01000000-0000-0000-0000-000000000000",
+		"00010000-0000-0000-0000-000000000000",
+		"00000100-0000-0000-0000-000000000000",
+		"00000001-0000-0000-0000-000000000000",
+		"00000000-0100-0000-0000-000000000000",
+		"00000000-0001-0000-0000-000000000000",
+		"00000000-0000-0100-0000-000000000000",
+		"00000000-0000-0001-0000-000000000000",
+		"00000000-0000-0000-0001-000000000000",
+		"00000000-0000-0000-0100-000000000000",
+		"00000000-0000-0000-0000-000000000001",
+		"00000000-0000-0000-0000-000000000100",
+		"00000000-0000-0000-0000-000000010000",
+		"00000000-0000-0000-0000-000001000000",
+		"00000000-0000-0000-0000-000100000000",
+		"00000000-0000-0000-0000-010000000000",
+	}
+	uuids := make([]UUID, len(ordered))
+	for i := range ordered {
+		uuids[len(ordered)-1-i], _ = NewFromString(ordered[i])
+	}
+	sort.Sort(SQLServerOrder(uuids))
+	for i, u := range uuids {
+		if u.String() != ordered[i] {
+			t.Errorf("TestCompareSQLServer[%d]: Expecting %s, got %s", i, ordered[i], u)
+		}
+	}
+	if act := CompareSQLServer(uuids[3], uuids[3]); act != 0 {
+		t.Errorf("TestCompareSQLServer: Expecting 0 for equal UUIDs, got %d", act)
+	}
+}
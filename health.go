@@ -0,0 +1,109 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	// cutoffs for 8 bits of min-entropy per byte and a false positive probability of 2^-40 per sample
+	defaultRepetitionCutoff = 6
+	defaultProportionCutoff = 19
+	// window size of the adaptive proportion test for non-binary samples
+	proportionWindow = 512
+)
+
+var (
+	// ErrRepetitionCount is returned when the repetition count health test fails.
+	ErrRepetitionCount = errors.New("uuid: repetition count health test failure")
+	// ErrAdaptiveProportion is returned when the adaptive proportion health test fails.
+	ErrAdaptiveProportion = errors.New("uuid: adaptive proportion health test failure")
+)
+
+// HealthTest runs the continuous health tests of NIST SP 800-90B section 4.4 over the bytes read from
+// a random source, to detect when it gets stuck or badly biased:
+//
+// The repetition count test fails when a byte repeats RepetitionCutoff times in a row.
+//
+// The adaptive proportion test fails when the first byte of a 512-byte window occurs ProportionCutoff times in the window.
+//
+// The default cutoffs assume 8 bits of min-entropy per byte, with a false positive probability of 2^-40 per byte.
+// After a failure, the tests start over with the next bytes.
+//
+// A HealthTest is safe for concurrent use; it must not be copied after first use.
+type HealthTest struct {
+	// RepetitionCutoff is the cutoff of the repetition count test; the default is 6.
+	RepetitionCutoff int
+	// ProportionCutoff is the cutoff of the adaptive proportion test; the default is 19.
+	ProportionCutoff int
+	// OnFailure is called with ErrRepetitionCount or ErrAdaptiveProportion for each failure.
+	OnFailure func(err error)
+
+	mutex sync.Mutex
+	// repetition count test state
+	started    bool
+	repeated   byte
+	repetition int
+	// adaptive proportion test state
+	first      byte
+	proportion int
+	window     int
+}
+
+// Test runs the health tests over the bytes, which continue the sequence of bytes previously tested.
+func (h *HealthTest) Test(b []byte) error {
+	repetitionCutoff := h.RepetitionCutoff
+	if repetitionCutoff <= 0 {
+		repetitionCutoff = defaultRepetitionCutoff
+	}
+	proportionCutoff := h.ProportionCutoff
+	if proportionCutoff <= 0 {
+		proportionCutoff = defaultProportionCutoff
+	}
+
+	var err error
+	h.mutex.Lock()
+	for _, sample := range b {
+		if !h.started || sample != h.repeated {
+			h.started, h.repeated, h.repetition = true, sample, 1
+		} else if h.repetition++; h.repetition >= repetitionCutoff {
+			err = ErrRepetitionCount
+		}
+
+		if h.window == 0 {
+			h.first, h.proportion = sample, 1
+		} else if sample == h.first {
+			if h.proportion++; h.proportion >= proportionCutoff && err == nil {
+				err = ErrAdaptiveProportion
+			}
+		}
+		if h.window++; h.window == proportionWindow {
+			h.window = 0
+		}
+
+		if err != nil {
+			h.started, h.window = false, 0
+			break
+		}
+	}
+	h.mutex.Unlock()
+
+	if err != nil && h.OnFailure != nil {
+		h.OnFailure(err)
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestHealthTest(t *testing.T) {
+	var failures []error
+	h := &HealthTest{OnFailure: func(err error) { failures = append(failures, err) }}
+
+	buf := make([]byte, 1<<20)
+	rand.Read(buf)
+	if err := h.Test(buf); err != nil {
+		t.Error("TestHealthTest(random):", err)
+	}
+
+	// repetition count, continued across calls
+	if err := h.Test([]byte{1, 7, 7, 7}); err != nil {
+		t.Error("TestHealthTest(3 repetitions):", err)
+	}
+	if err := h.Test([]byte{7, 7, 2}); err != nil {
+		t.Error("TestHealthTest(5 repetitions):", err)
+	}
+	if err := h.Test([]byte{9, 9, 9}); err != nil {
+		t.Error("TestHealthTest(3 repetitions):", err)
+	}
+	if err := h.Test([]byte{9, 9, 9}); err != ErrRepetitionCount {
+		t.Errorf("TestHealthTest(6 repetitions): Expecting %v, got %v", ErrRepetitionCount, err)
+	}
+	// starts over after a failure
+	if err := h.Test(bytes.Repeat([]byte{3}, 5)); err != nil {
+		t.Error("TestHealthTest(after failure):", err)
+	}
+
+	// adaptive proportion, within a fresh window
+	for _, tc := range []struct {
+		occurrences int
+		err         error
+	}{
+		{18, nil},
+		{19, ErrAdaptiveProportion},
+	} {
+		h := &HealthTest{}
+		window := make([]byte, proportionWindow)
+		for i := range window {
+			window[i] = byte(i%250 + 1)
+		}
+		for i := 0; i < tc.occurrences; i++ {
+			window[i*20] = 0
+		}
+		if err := h.Test(window); err != tc.err {
+			t.Errorf("TestHealthTest(%d occurrences): Expecting %v, got %v", tc.occurrences, tc.err, err)
+		}
+	}
+
+	if len(failures) != 1 || failures[0] != ErrRepetitionCount {
+		t.Errorf("TestHealthTest: Expecting OnFailure with %v, got %v", ErrRepetitionCount, failures)
+	}
+
+	h = &HealthTest{RepetitionCutoff: 3, ProportionCutoff: 4}
+	if err := h.Test([]byte{5, 5, 5}); err != ErrRepetitionCount {
+		t.Errorf("TestHealthTest(custom cutoff): Expecting %v, got %v", ErrRepetitionCount, err)
+	}
+	if err := h.Test([]byte{5, 1, 5, 2, 5, 3, 5}); err != ErrAdaptiveProportion {
+		t.Errorf("TestHealthTest(custom cutoff): Expecting %v, got %v", ErrAdaptiveProportion, err)
+	}
+}
+
+// stuckReader always reads zero bytes.
+type stuckReader struct{}
+
+func (stuckReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
+
+func TestGeneratorHealthTest(t *testing.T) {
+	defer func() { randReader = rand.Reader }()
+
+	failures := 0
+	h := &HealthTest{OnFailure: func(error) { failures++ }}
+	g, err := NewGenerator(GeneratorConfig{EntropyPolicy: EntropyError, HealthTest: h})
+	if err != nil {
+		t.Fatal("TestGeneratorHealthTest:", err)
+	}
+	randReader = stuckReader{}
+	if u, err := g.NewCryptoE(); err == nil {
+		t.Errorf("TestGeneratorHealthTest: expecting error, got %s", u)
+	}
+	if failures != 1 {
+		t.Errorf("TestGeneratorHealthTest: Expecting 1 failure, got %d", failures)
+	}
+
+	randReader = rand.Reader
+	g, _ = NewGenerator(GeneratorConfig{HealthTest: h})
+	randReader = stuckReader{}
+	if _, err = g.NewCryptoE(); err != nil {
+		t.Error("TestGeneratorHealthTest(EntropyFallback):", err)
+	}
+	if g.EntropyFallbacks() != 1 {
+		t.Errorf("TestGeneratorHealthTest(EntropyFallback): Expecting 1 fallback, got %d", g.EntropyFallbacks())
+	}
+
+	// construction time
+	failures = 0
+	if _, err = NewGenerator(GeneratorConfig{HealthTest: h}); err == nil || failures != 1 {
+		t.Errorf("TestGeneratorHealthTest(construction): Expecting error and 1 failure, got %v and %d", err, failures)
+	}
+
+	// package-level, with the default generator
+	randReader = rand.Reader
+	g, _ = NewGenerator(GeneratorConfig{EntropyPolicy: EntropyError, HealthTest: h})
+	setDefaultGenerator(t, g)
+	randReader = stuckReader{}
+	for _, shard := range g.shards {
+		shard.randBufOffset = randBufCap
+	}
+	failures = 0
+	if u, err := NewCryptoE(); err == nil || failures != 1 {
+		t.Errorf("TestGeneratorHealthTest(default generator): Expecting error and 1 failure, got %s, %v and %d", u, err, failures)
+	}
+}
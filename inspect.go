@@ -0,0 +1,151 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Info is a structured breakdown of a UUID, as returned by UUID.Inspect.
+// Fields that do not apply to the UUID are nil or empty, and omitted from the JSON output.
+type Info struct {
+	UUID        string `json:"uuid"`
+	Version     int    `json:"version"`
+	VersionName string `json:"versionName,omitempty"`
+	Variant     int    `json:"variant"`
+	VariantName string `json:"variantName"`
+	// Time is set for time-based versions (1, 6 and 7).
+	Time *time.Time `json:"time,omitempty"`
+	// ClockSequence is set for versions 1 and 6.
+	ClockSequence *uint16 `json:"clockSequence,omitempty"`
+	// Node is the 48-bit node identifier, set for versions 1 and 6.
+	Node string `json:"node,omitempty"`
+	// NodeId is the 30-bit node id managed by SetNodeId, set for version 1.
+	NodeId *uint32 `json:"nodeId,omitempty"`
+	// RandomNode reports whether the node identifier has both the 'multicast' and 'local' bits set,
+	// marking it as a random value rather than an IEEE 802 address, as generated by this package.
+	RandomNode *bool `json:"randomNode,omitempty"`
+	// Namespace names the well-known name space if the UUID is one of the IDs defined in RFC 4122 appendix C.
+	// The name space used to create a v3 or v5 UUID cannot be recovered from the hash.
+	Namespace string   `json:"namespace,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+var versionNames = map[int]string{
+	1: "time-based",
+	2: "DCE security",
+	3: "name-based (MD5)",
+	4: "random",
+	5: "name-based (SHA-1)",
+	6: "reordered time-based",
+	7: "Unix time-based",
+	8: "custom",
+}
+
+var namespaceNames = []struct {
+	uuid UUID
+	name string
+}{
+	{NamespaceDNS, "DNS"},
+	{NamespaceURL, "URL"},
+	{NamespaceOID, "OID"},
+	{NamespaceX500, "X500"},
+}
+
+// Inspect returns a structured breakdown of the receiver UUID, for debugging and tooling.
+func (u UUID) Inspect() Info {
+	if len(u) != 16 {
+		return Info{
+			UUID:     fmt.Sprintf("%x", []byte(u)),
+			Warnings: []string{fmt.Sprintf("invalid length (%d instead of 16)", len(u))},
+		}
+	}
+
+	info := Info{
+		UUID:        u.String(),
+		Version:     u.Version(),
+		VersionName: versionNames[u.Version()],
+		Variant:     u.Variant(),
+		VariantName: variantName(u.Variant()),
+	}
+	switch {
+	case bytes.Equal(u, zero16[:]):
+		info.Warnings = append(info.Warnings, "nil UUID")
+		return info
+	case bytes.Equal(u, max16[:]):
+		info.Warnings = append(info.Warnings, "max UUID")
+		return info
+	}
+	if info.VersionName == "" {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("unknown version %d", info.Version))
+	}
+
+	var ts time.Time
+	switch info.Version {
+	case 1:
+		ts = u.Time()
+		nodeId := u.NodeId()
+		info.NodeId = &nodeId
+	case 6:
+		gts := int64(binary.BigEndian.Uint32(u[0:4]))<<28 | int64(binary.BigEndian.Uint16(u[4:6]))<<12 |
+			int64(binary.BigEndian.Uint16(u[6:8])&0x0fff)
+		nanosecs := toUnixNano(gts)
+		ts = time.Unix(nanosecs/1e9, nanosecs%1e9).UTC()
+	case 7:
+		ms := int64(binary.BigEndian.Uint64(u[0:8]) >> 16)
+		ts = time.Unix(ms/1e3, (ms%1e3)*1e6).UTC()
+	}
+	if !ts.IsZero() {
+		info.Time = &ts
+		if ts.After(timeNow().Add(time.Minute)) {
+			info.Warnings = append(info.Warnings, "time is in the future")
+		}
+	}
+	if info.Version == 1 || info.Version == 6 {
+		clockSeq := u.ClockSequence()
+		info.ClockSequence = &clockSeq
+		info.Node = fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", u[10], u[11], u[12], u[13], u[14], u[15])
+		random := u[10]&0x03 == 0x03
+		info.RandomNode = &random
+	}
+	for _, ns := range namespaceNames {
+		if bytes.Equal(u, ns.uuid) {
+			info.Namespace = ns.name
+		}
+	}
+
+	return info
+}
+
+var (
+	zero16 [16]byte
+	max16  = [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+)
+
+// variantName returns the description of a variant, as returned by UUID.Variant.
+func variantName(v int) string {
+	switch {
+	case v < 4:
+		return "NCS"
+	case v < 6:
+		return "RFC 4122"
+	case v == 6:
+		return "Microsoft"
+	}
+	return "future"
+}
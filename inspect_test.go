@@ -0,0 +1,124 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	now := time.Date(2024, 2, 29, 12, 34, 56, 789012300, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() { timeNow = time.Now }()
+
+	uuid1 := New()
+	info := uuid1.Inspect()
+	if info.UUID != uuid1.String() || info.Version != 1 || info.VersionName != "time-based" {
+		t.Errorf("TestInspect(New): unexpected %+v", info)
+	}
+	if info.Variant != uuid1.Variant() || info.VariantName != "NCS" {
+		t.Errorf("TestInspect(New): unexpected variant %d %s", info.Variant, info.VariantName)
+	}
+	if info.Time == nil || !info.Time.Equal(now) {
+		t.Errorf("TestInspect(New): expecting time %s, got %v", now, info.Time)
+	}
+	if info.NodeId == nil || *info.NodeId != NodeId() {
+		t.Errorf("TestInspect(New): expecting node id %x, got %v", NodeId(), info.NodeId)
+	}
+	if info.ClockSequence == nil || *info.ClockSequence != uint16(uuid1[8]&0x1f)<<8|uint16(uuid1[9]) {
+		t.Errorf("TestInspect(New): unexpected clock sequence %v", info.ClockSequence)
+	}
+	if info.RandomNode == nil || !*info.RandomNode {
+		t.Error("TestInspect(New): expecting random node")
+	}
+	if len(info.Node) != 17 || info.Namespace != "" || len(info.Warnings) != 0 {
+		t.Errorf("TestInspect(New): unexpected %+v", info)
+	}
+
+	info = NewV7().Inspect()
+	if info.Version != 7 || info.VariantName != "RFC 4122" || info.Time == nil || !info.Time.Equal(now.Truncate(time.Millisecond)) {
+		t.Errorf("TestInspect(NewV7): unexpected %+v", info)
+	}
+	if info.ClockSequence != nil || info.NodeId != nil || info.RandomNode != nil || info.Node != "" {
+		t.Errorf("TestInspect(NewV7): unexpected v1 fields in %+v", info)
+	}
+
+	// RFC 9562 appendix A.5
+	uuid6, _ := NewFromString("1ec9414c-232a-6b00-b3c8-9f6bdeced846")
+	info = uuid6.Inspect()
+	if want := time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC); info.Time == nil || !info.Time.Equal(want) {
+		t.Errorf("TestInspect(v6): expecting time %s, got %v", want, info.Time)
+	}
+	if info.ClockSequence == nil || *info.ClockSequence != 0x33c8 || info.Node != "9f:6b:de:ce:d8:46" {
+		t.Errorf("TestInspect(v6): unexpected %+v", info)
+	}
+	if info.NodeId != nil || info.RandomNode == nil || !*info.RandomNode {
+		t.Errorf("TestInspect(v6): unexpected node fields in %+v", info)
+	}
+
+	info = NamespaceURL.Inspect()
+	if info.Namespace != "URL" || info.Version != 1 || *info.RandomNode {
+		t.Errorf("TestInspect(NamespaceURL): unexpected %+v", info)
+	}
+
+	future, _ := NewFromString("ffffffff-ffff-7fff-bfff-ffffffffffff")
+	for _, tc := range []struct {
+		uuid    UUID
+		warning string
+	}{
+		{UUID(zero), "nil UUID"},
+		{UUID(max16[:]), "max UUID"},
+		{UUID(zero[1:]), "invalid length (15 instead of 16)"},
+		{future, "time is in the future"},
+		{UUID{0, 0, 0, 0, 0, 0, 0xf0, 0, 0x80, 0, 0, 0, 0, 0, 0, 0}, "unknown version 15"},
+	} {
+		info = tc.uuid.Inspect()
+		if len(info.Warnings) != 1 || info.Warnings[0] != tc.warning {
+			t.Errorf("TestInspect(%x): expecting warning %q, got %v", []byte(tc.uuid), tc.warning, info.Warnings)
+		}
+	}
+}
+
+func TestInspectJSON(t *testing.T) {
+	b, err := json.Marshal(NamespaceDNS.Inspect())
+	if err != nil {
+		t.Fatal("TestInspectJSON:", err)
+	}
+	act := string(b)
+	for _, s := range []string{
+		`"uuid":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`,
+		`"version":1`,
+		`"versionName":"time-based"`,
+		`"variant":4`,
+		`"variantName":"RFC 4122"`,
+		`"time":"1998-02-04T22:13:53.1511824Z"`,
+		`"clockSequence":180`,
+		`"node":"00:c0:4f:d4:30:c8"`,
+		`"randomNode":false`,
+		`"namespace":"DNS"`,
+	} {
+		if !strings.Contains(act, s) {
+			t.Errorf("TestInspectJSON: expecting %s in %s", s, act)
+		}
+	}
+	if strings.Contains(act, "warnings") {
+		t.Errorf("TestInspectJSON: unexpected warnings in %s", act)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultLeaseTTL = 30 * time.Second
+
+// aliases to allow mocking in tests
+var osLink = os.Link
+
+// NodeLeaser hands out node ids that are not in use by other processes sharing the same leaser configuration.
+type NodeLeaser interface {
+	// Acquire claims an unused node id, until it is released.
+	Acquire() (uint32, error)
+	// Release releases a node id claimed by Acquire, making it available to other processes.
+	Release(nodeId uint32) error
+}
+
+// NodeFromLeaser provides a node id acquired from the leaser. The node id should be released
+// when the generator is no longer used, e.g. at shutdown:
+//
+//	leaser := &uuid.FileNodeLeaser{Dir: "/run/myapp", Max: 1023, OnLost: func(nodeId uint32, err error) {
+//		log.Fatal(err)
+//	}}
+//	g, err := uuid.NewGenerator(uuid.GeneratorConfig{Node: uuid.NodeFromLeaser(leaser)})
+//	...
+//	defer leaser.Release(g.NodeId())
+func NodeFromLeaser(l NodeLeaser) NodeProvider {
+	return nodeProviderFunc(func() (uint64, bool, error) {
+		nodeId, err := l.Acquire()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(nodeId), false, nil
+	})
+}
+
+// FileNodeLeaser is a NodeLeaser that coordinates the processes on a host (or sharing a file system)
+// through lock files in a directory, one per leased node id, without an external coordinator.
+//
+// The lock files are created atomically and refreshed periodically by a heartbeat while the node id is leased.
+// A lock file that has not been refreshed for longer than the TTL, e.g. because its process crashed,
+// is considered expired and its node id is claimed again.
+//
+// A FileNodeLeaser is safe for concurrent use; it must not be copied after first use.
+type FileNodeLeaser struct {
+	// Dir is the directory holding the lock files; it is created if needed.
+	Dir string
+	// Min and Max bound the range of node ids that can be leased (inclusive); Max must not be less than Min.
+	Min, Max uint32
+	// TTL is the duration after which a lease that has not been refreshed expires; the default is 30 seconds.
+	// The heartbeat refreshes the leases every third of the TTL.
+	TTL time.Duration
+	// OnLost is called if a heartbeat finds that a lease was taken over by another process, e.g. after
+	// the process was suspended for longer than the TTL, or could not be refreshed before it expired.
+	// The node id must then no longer be used, e.g. by stopping the generator or the process.
+	// OnLost is required, so that the loss of a lease cannot go unnoticed.
+	OnLost func(nodeId uint32, err error)
+
+	mutex  sync.Mutex
+	leases map[uint32]*fileLease
+}
+
+// fileLease is a lease held by the current process.
+type fileLease struct {
+	path  string
+	token []byte
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// Acquire claims the lowest node id in the range that is not leased, or whose lease expired.
+func (f *FileNodeLeaser) Acquire() (uint32, error) {
+	if f.Max < f.Min {
+		return 0, fmt.Errorf("uuid.FileNodeLeaser.Acquire: invalid node id range %d-%d", f.Min, f.Max)
+	}
+	if f.Max>>30 != 0 {
+		return 0, fmt.Errorf("uuid.FileNodeLeaser.Acquire: node id %d does not fit in 30 bits", f.Max)
+	}
+	if f.OnLost == nil {
+		return 0, fmt.Errorf("uuid.FileNodeLeaser.Acquire: OnLost is not set")
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return 0, fmt.Errorf("uuid.FileNodeLeaser.Acquire: %v", err)
+	}
+	token, err := leaseToken()
+	if err != nil {
+		return 0, fmt.Errorf("uuid.FileNodeLeaser.Acquire: %v", err)
+	}
+
+	for nodeId := f.Min; ; nodeId++ {
+		path := f.path(nodeId)
+		ok, err := f.claim(path, token)
+		if err != nil {
+			return 0, fmt.Errorf("uuid.FileNodeLeaser.Acquire: %v", err)
+		}
+		if ok {
+			lease := &fileLease{path, token, make(chan struct{}), make(chan struct{})}
+			f.mutex.Lock()
+			if f.leases == nil {
+				f.leases = make(map[uint32]*fileLease)
+			}
+			f.leases[nodeId] = lease
+			f.mutex.Unlock()
+			go f.heartbeat(nodeId, lease)
+			return nodeId, nil
+		}
+		if nodeId == f.Max {
+			break
+		}
+	}
+	return 0, fmt.Errorf("uuid.FileNodeLeaser.Acquire: all node ids in range %d-%d are leased", f.Min, f.Max)
+}
+
+// Release stops the heartbeat of the lease and removes its lock file, unless it was taken over by another process.
+func (f *FileNodeLeaser) Release(nodeId uint32) error {
+	f.mutex.Lock()
+	lease := f.leases[nodeId]
+	delete(f.leases, nodeId)
+	f.mutex.Unlock()
+	if lease == nil {
+		return fmt.Errorf("uuid.FileNodeLeaser.Release: node id %d is not leased", nodeId)
+	}
+	close(lease.stop)
+	<-lease.done
+
+	// move the lock file out of the way before checking it, so that a lock file created by another process
+	// taking over the lease in the meantime cannot be removed; if it is not ours, put it back
+	released := lease.path + ".released." + string(lease.token[:8])
+	if err := os.Rename(lease.path, released); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("uuid.FileNodeLeaser.Release: %v", err)
+	}
+	if content, err := os.ReadFile(released); err != nil || !bytes.Equal(content, lease.token) {
+		// taken over by another process; if yet another process created a lock file in the meantime,
+		// leave this one aside rather than removing the lock file of a live lease
+		if err := osLink(released, lease.path); err != nil {
+			return fmt.Errorf("uuid.FileNodeLeaser.Release: could not restore the lock file of another process, left at %s: %v", released, err)
+		}
+	}
+	if err := os.Remove(released); err != nil {
+		return fmt.Errorf("uuid.FileNodeLeaser.Release: %v", err)
+	}
+	return nil
+}
+
+// ttl returns the configured TTL or the default.
+func (f *FileNodeLeaser) ttl() time.Duration {
+	if f.TTL <= 0 {
+		return defaultLeaseTTL
+	}
+	return f.TTL
+}
+
+// path returns the path of the lock file of the node id.
+func (f *FileNodeLeaser) path(nodeId uint32) string {
+	return filepath.Join(f.Dir, fmt.Sprintf("node-%d.lock", nodeId))
+}
+
+// claim tries to create the lock file, taking it over if it expired. It reports whether the lease was claimed.
+func (f *FileNodeLeaser) claim(path string, token []byte) (bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, err = file.Write(token)
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return false, err
+			}
+			return true, nil
+		}
+		if !os.IsExist(err) {
+			return false, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// released in the meantime
+				continue
+			}
+			return false, err
+		}
+		if time.Since(info.ModTime()) <= f.ttl() {
+			return false, nil
+		}
+		// move the expired lock file out of the way; if another process renewed or replaced it
+		// in the meantime, put it back
+		stale := path + ".expired." + string(token[:8])
+		if err = os.Rename(path, stale); err != nil {
+			continue
+		}
+		moved, err := os.Stat(stale)
+		if err == nil && os.SameFile(info, moved) && moved.ModTime().Equal(info.ModTime()) {
+			// still the expired lock file
+			os.Remove(stale)
+		} else if err == nil && osLink(stale, path) == nil {
+			os.Remove(stale)
+		}
+		// otherwise leave it aside, rather than removing the lock file of a live lease
+	}
+	return false, nil
+}
+
+// heartbeat refreshes the lock file until the lease is released, checking that it was not taken over.
+// Errors, such as a lock file briefly moved aside by another process, are retried until the lease may have expired.
+func (f *FileNodeLeaser) heartbeat(nodeId uint32, lease *fileLease) {
+	defer close(lease.done)
+	ttl := f.ttl()
+	refreshed := time.Now()
+	timer := time.NewTimer(ttl / 3)
+	defer timer.Stop()
+	for {
+		select {
+		case <-lease.stop:
+			return
+		case <-timer.C:
+		}
+		content, err := os.ReadFile(lease.path)
+		if err == nil && !bytes.Equal(content, lease.token) {
+			f.OnLost(nodeId, fmt.Errorf("uuid.FileNodeLeaser: lost lease of node id %d: lock file %s was taken over by another process", nodeId, lease.path))
+			return
+		}
+		if err == nil {
+			now := time.Now()
+			if err = os.Chtimes(lease.path, now, now); err == nil {
+				refreshed = now
+				timer.Reset(ttl / 3)
+				continue
+			}
+		}
+		if time.Since(refreshed) >= ttl {
+			f.OnLost(nodeId, fmt.Errorf("uuid.FileNodeLeaser: lost lease of node id %d: %v", nodeId, err))
+			return
+		}
+		timer.Reset(ttl / 30)
+	}
+}
+
+// leaseToken returns a random token identifying a lease, followed by the process id for troubleshooting.
+func leaseToken() ([]byte, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%s %d\n", hex.EncodeToString(b), os.Getpid())), nil
+}
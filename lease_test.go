@@ -0,0 +1,220 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileNodeLeaser(t *testing.T) {
+	dir := t.TempDir()
+	// two leasers sharing the directory, as two processes would
+	l1 := &FileNodeLeaser{Dir: dir, Min: 10, Max: 12, OnLost: failOnLost(t)}
+	l2 := &FileNodeLeaser{Dir: dir, Min: 10, Max: 12, OnLost: failOnLost(t)}
+
+	var ids []uint32
+	for _, l := range []*FileNodeLeaser{l1, l2, l1} {
+		nodeId, err := l.Acquire()
+		if err != nil {
+			t.Fatal("TestFileNodeLeaser:", err)
+		}
+		ids = append(ids, nodeId)
+	}
+	if ids[0] != 10 || ids[1] != 11 || ids[2] != 12 {
+		t.Errorf("TestFileNodeLeaser: Expecting [10 11 12], got %v", ids)
+	}
+	if _, err := l2.Acquire(); err == nil {
+		t.Error("TestFileNodeLeaser(exhausted): expecting error, got nil")
+	}
+	if err := l2.Release(10); err == nil {
+		t.Error("TestFileNodeLeaser(release not leased): expecting error, got nil")
+	}
+	if err := l1.Release(10); err != nil {
+		t.Error("TestFileNodeLeaser:", err)
+	}
+	if nodeId, err := l2.Acquire(); err != nil || nodeId != 10 {
+		t.Errorf("TestFileNodeLeaser: Expecting 10 after release, got %d, %v", nodeId, err)
+	}
+	for _, nodeId := range []uint32{11, 10} {
+		if err := l2.Release(nodeId); err != nil {
+			t.Error("TestFileNodeLeaser:", err)
+		}
+	}
+	if err := l1.Release(12); err != nil {
+		t.Error("TestFileNodeLeaser:", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("TestFileNodeLeaser: Expecting no lock files left, got %v", files)
+	}
+
+	if _, err := (&FileNodeLeaser{Dir: dir, Min: 2, Max: 1}).Acquire(); err == nil {
+		t.Error("TestFileNodeLeaser(invalid range): expecting error, got nil")
+	}
+	if _, err := (&FileNodeLeaser{Dir: dir, Max: 1 << 30}).Acquire(); err == nil {
+		t.Error("TestFileNodeLeaser(30-bit overflow): expecting error, got nil")
+	}
+	if nodeId, err := (&FileNodeLeaser{Dir: dir, Max: 1}).Acquire(); err == nil {
+		t.Errorf("TestFileNodeLeaser(OnLost not set): expecting error, got node id %d", nodeId)
+	}
+}
+
+// failOnLost returns an OnLost function failing the test.
+func failOnLost(t *testing.T) func(uint32, error) {
+	return func(nodeId uint32, err error) {
+		t.Errorf("unexpected loss of node id %d: %v", nodeId, err)
+	}
+}
+
+func TestFileNodeLeaserExpiry(t *testing.T) {
+	dir := t.TempDir()
+	// a lock file left behind by a crashed process
+	path := filepath.Join(dir, "node-0.lock")
+	if err := os.WriteFile(path, []byte("crashed 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	os.Chtimes(path, old, old)
+
+	l := &FileNodeLeaser{Dir: dir, Max: 1, TTL: 30 * time.Millisecond, OnLost: failOnLost(t)}
+	if nodeId, err := l.Acquire(); err != nil || nodeId != 0 {
+		t.Fatalf("TestFileNodeLeaserExpiry: Expecting expired node id 0, got %d, %v", nodeId, err)
+	}
+	// the heartbeat keeps the lease from expiring
+	time.Sleep(100 * time.Millisecond)
+	if nodeId, err := (&FileNodeLeaser{Dir: dir, Max: 0, TTL: 30 * time.Millisecond, OnLost: failOnLost(t)}).Acquire(); err == nil {
+		t.Errorf("TestFileNodeLeaserExpiry: Expecting leased node id, got %d", nodeId)
+	}
+	if err := l.Release(0); err != nil {
+		t.Error("TestFileNodeLeaserExpiry:", err)
+	}
+}
+
+func TestFileNodeLeaserLost(t *testing.T) {
+	var (
+		mutex sync.Mutex
+		lost  []uint32
+	)
+	l := &FileNodeLeaser{Dir: t.TempDir(), TTL: 30 * time.Millisecond, OnLost: func(nodeId uint32, err error) {
+		mutex.Lock()
+		lost = append(lost, nodeId)
+		mutex.Unlock()
+	}}
+	nodeId, err := l.Acquire()
+	if err != nil {
+		t.Fatal("TestFileNodeLeaserLost:", err)
+	}
+	// another process took over the lease
+	os.WriteFile(l.path(nodeId), []byte("other 2\n"), 0o644)
+	time.Sleep(100 * time.Millisecond)
+	mutex.Lock()
+	if len(lost) != 1 || lost[0] != nodeId {
+		t.Errorf("TestFileNodeLeaserLost: Expecting [%d], got %v", nodeId, lost)
+	}
+	mutex.Unlock()
+	if err = l.Release(nodeId); err != nil {
+		t.Error("TestFileNodeLeaserLost:", err)
+	}
+	if content, _ := os.ReadFile(l.path(nodeId)); string(content) != "other 2\n" {
+		t.Errorf("TestFileNodeLeaserLost: Release removed the lock file of another process")
+	}
+	if files, _ := filepath.Glob(filepath.Join(l.Dir, "*")); len(files) != 1 {
+		t.Errorf("TestFileNodeLeaserLost: Expecting only the lock file of another process left, got %v", files)
+	}
+}
+
+func TestFileNodeLeaserReleaseRace(t *testing.T) {
+	defer func() { osLink = os.Link }()
+	l := &FileNodeLeaser{Dir: t.TempDir(), OnLost: func(uint32, error) {}}
+	nodeId, err := l.Acquire()
+	if err != nil {
+		t.Fatal("TestFileNodeLeaserReleaseRace:", err)
+	}
+	path := l.path(nodeId)
+	// another process took over the lease, and yet another one creates a lock file while it is moved aside
+	os.WriteFile(path, []byte("other 2\n"), 0o644)
+	osLink = func(oldname, newname string) error {
+		os.WriteFile(newname, []byte("third 3\n"), 0o644)
+		return os.Link(oldname, newname)
+	}
+	if err = l.Release(nodeId); err == nil {
+		t.Error("TestFileNodeLeaserReleaseRace: expecting error, got nil")
+	}
+	if content, _ := os.ReadFile(path); string(content) != "third 3\n" {
+		t.Errorf("TestFileNodeLeaserReleaseRace: Expecting the lock file of the third process, got %q", content)
+	}
+	aside, _ := filepath.Glob(path + ".released.*")
+	if len(aside) != 1 {
+		t.Fatalf("TestFileNodeLeaserReleaseRace: Expecting the lock file of the other process left aside, got %v", aside)
+	}
+	if content, _ := os.ReadFile(aside[0]); string(content) != "other 2\n" {
+		t.Errorf("TestFileNodeLeaserReleaseRace: Expecting the lock file of the other process left aside, got %q", content)
+	}
+}
+
+func TestFileNodeLeaserTransient(t *testing.T) {
+	var (
+		mutex sync.Mutex
+		lost  []error
+	)
+	l := &FileNodeLeaser{Dir: t.TempDir(), TTL: 300 * time.Millisecond, OnLost: func(nodeId uint32, err error) {
+		mutex.Lock()
+		lost = append(lost, err)
+		mutex.Unlock()
+	}}
+	nodeId, err := l.Acquire()
+	if err != nil {
+		t.Fatal("TestFileNodeLeaserTransient:", err)
+	}
+	// the lock file is briefly moved aside, across a heartbeat
+	path := l.path(nodeId)
+	time.Sleep(50 * time.Millisecond)
+	os.Rename(path, path+".aside")
+	time.Sleep(100 * time.Millisecond)
+	os.Rename(path+".aside", path)
+	time.Sleep(150 * time.Millisecond)
+	mutex.Lock()
+	if len(lost) != 0 {
+		t.Errorf("TestFileNodeLeaserTransient: Expecting no loss, got %v", lost)
+	}
+	mutex.Unlock()
+
+	// the lock file disappears for longer than the TTL
+	os.Remove(path)
+	time.Sleep(400 * time.Millisecond)
+	mutex.Lock()
+	if len(lost) != 1 {
+		t.Errorf("TestFileNodeLeaserTransient: Expecting 1 loss, got %v", lost)
+	}
+	mutex.Unlock()
+	l.Release(nodeId)
+}
+
+func TestNodeFromLeaser(t *testing.T) {
+	l := &FileNodeLeaser{Dir: t.TempDir(), Min: 7, Max: 8, OnLost: failOnLost(t)}
+	g, err := NewGenerator(GeneratorConfig{Node: NodeFromLeaser(l)})
+	if err != nil {
+		t.Fatal("TestNodeFromLeaser:", err)
+	}
+	if act := g.New().NodeId(); act != 7 {
+		t.Errorf("TestNodeFromLeaser: Expecting node id 7, got %d", act)
+	}
+	if err = l.Release(g.NodeId()); err != nil {
+		t.Error("TestNodeFromLeaser:", err)
+	}
+}
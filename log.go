@@ -0,0 +1,114 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// DefaultLogKey is the attribute key used by LogHandler if none is set.
+const DefaultLogKey = "request_id"
+
+// logDetail is set by SetLogDetail.
+var logDetail atomic.Bool
+
+// SetLogDetail sets whether UUIDs logged with log/slog render as a group of their canonical string,
+// version and, for time-based versions, time, instead of just the canonical string (the default).
+func SetLogDetail(detail bool) {
+	logDetail.Store(detail)
+}
+
+// LogValue implements slog.LogValuer, rendering the receiver UUID as its canonical string,
+// or as a group if SetLogDetail is enabled. A UUID with an invalid length renders as a hex string.
+func (u UUID) LogValue() slog.Value {
+	if logDetail.Load() {
+		return u.logGroup()
+	}
+	if len(u) != 16 {
+		return slog.StringValue(u.Hex())
+	}
+	return slog.StringValue(u.String())
+}
+
+// logGroup returns the group value rendering the UUID, its version and time.
+func (u UUID) logGroup() slog.Value {
+	info := u.Inspect()
+	attrs := []slog.Attr{slog.String("uuid", info.UUID)}
+	if len(u) == 16 {
+		attrs = append(attrs, slog.Int("version", info.Version))
+	}
+	if info.Time != nil {
+		attrs = append(attrs, slog.Time("time", *info.Time))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Attr returns a slog.Attr for the UUID, rendered as set by SetLogDetail.
+func Attr(key string, u UUID) slog.Attr {
+	return slog.Any(key, u)
+}
+
+// DetailAttr returns a slog.Attr for the UUID, always rendered as a group of its canonical string,
+// version and, for time-based versions, time.
+func DetailAttr(key string, u UUID) slog.Attr {
+	return slog.Attr{Key: key, Value: u.logGroup()}
+}
+
+// LogHandler is a slog.Handler adding the request UUID carried by the context of each record,
+// as set by NewContext, to the records it passes on to the wrapped handler:
+//
+//	logger := slog.New(uuid.NewLogHandler(slog.NewJSONHandler(os.Stderr, nil), ""))
+//	logger.InfoContext(ctx, "processed")
+//
+// The attribute is added after those of the record, in the group opened by WithGroup, if any.
+type LogHandler struct {
+	handler slog.Handler
+	key     string
+}
+
+// NewLogHandler creates a LogHandler wrapping the handler, adding the request UUID with the key,
+// or DefaultLogKey if empty.
+func NewLogHandler(handler slog.Handler, key string) *LogHandler {
+	if key == "" {
+		key = DefaultLogKey
+	}
+	return &LogHandler{handler, key}
+}
+
+// Enabled reports whether the wrapped handler handles records at the level.
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle adds the request UUID from the context, if any, to the record and passes it on to the wrapped handler.
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if u, ok := FromContext(ctx); ok {
+		r = r.Clone()
+		r.AddAttrs(Attr(h.key, u))
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a LogHandler wrapping the wrapped handler with the attributes.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{h.handler.WithAttrs(attrs), h.key}
+}
+
+// WithGroup returns a LogHandler wrapping the wrapped handler with the group.
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{h.handler.WithGroup(name), h.key}
+}
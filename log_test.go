@@ -0,0 +1,92 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	u, _ := NewFromString("f254df4a-184c-1019-80a4-c61cd00a6899")
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger.Info("test", "id", u, Attr("attr", u))
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("TestLogValue: %v", err)
+	}
+	for _, key := range []string{"id", "attr"} {
+		if rec[key] != u.String() {
+			t.Errorf("TestLogValue: Expecting %s to be %s, got %v", key, u, rec[key])
+		}
+	}
+
+	SetLogDetail(true)
+	defer SetLogDetail(false)
+	buf.Reset()
+	v4, _ := NewFromString("6ba7b810-9dad-41d1-80b4-00c04fd430c8")
+	logger.Info("test", "id", u, DetailAttr("v4", v4))
+	rec = nil
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("TestLogValue: %v", err)
+	}
+	group, _ := rec["id"].(map[string]any)
+	if group["uuid"] != u.String() || group["version"] != 1.0 || group["time"] != u.Time().Format("2006-01-02T15:04:05.999999999Z07:00") {
+		t.Errorf("TestLogValue: Unexpected detail group %v", rec["id"])
+	}
+	if group, _ := rec["v4"].(map[string]any); group["version"] != 4.0 || group["time"] != nil {
+		t.Errorf("TestLogValue: Unexpected detail group %v", rec["v4"])
+	}
+
+	SetLogDetail(false)
+	if v := UUID([]byte{1, 2}).LogValue(); v.String() != "0102" {
+		t.Errorf("TestLogValue: Expecting invalid UUID to render as 0102, got %s", v)
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	u := New()
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil), ""))
+
+	logger.InfoContext(NewContext(context.Background(), u), "test")
+	logger.InfoContext(context.Background(), "test")
+	logger.With("a", 1).WithGroup("g").InfoContext(NewContext(context.Background(), u), "test", "b", 2)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("TestLogHandler: Expecting 3 records, got %d", len(lines))
+	}
+	recs := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal(line, &recs[i]); err != nil {
+			t.Fatalf("TestLogHandler: %v", err)
+		}
+	}
+	if recs[0][DefaultLogKey] != u.String() {
+		t.Errorf("TestLogHandler: Expecting %s, got %v", u, recs[0][DefaultLogKey])
+	}
+	if _, ok := recs[1][DefaultLogKey]; ok {
+		t.Errorf("TestLogHandler: Expecting no request id without context, got %v", recs[1][DefaultLogKey])
+	}
+	if group, _ := recs[2]["g"].(map[string]any); recs[2]["a"] != 1.0 || group["b"] != 2.0 || group[DefaultLogKey] != u.String() {
+		t.Errorf("TestLogHandler: Unexpected record %s", lines[2])
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// TimestampMasker converts v7 UUIDs to v4 UUIDs and back by XOR-masking the 48-bit timestamp
+// with a keyed SipHash-2-4 of the random bits, following the UUIDv47 construction
+// (see https://github.com/stateless-me/uuidv47).
+//
+// This keeps database keys time-sortable internally (v7), while the public form (v4)
+// does not reveal the creation time. The random bits are left unchanged, so the conversion is
+// an exact round-trip and costs a single hash computation.
+type TimestampMasker struct {
+	k0, k1 uint64
+}
+
+// NewTimestampMasker creates a TimestampMasker using the provided 128-bit SipHash key.
+func NewTimestampMasker(key []byte) (*TimestampMasker, error) {
+	if len(key) != 16 {
+		return nil, fmt.Errorf("uuid.NewTimestampMasker: Key length is wrong (%d instead of 16)", len(key))
+	}
+	return &TimestampMasker{
+		k0: binary.LittleEndian.Uint64(key[0:8]),
+		k1: binary.LittleEndian.Uint64(key[8:16]),
+	}, nil
+}
+
+// Mask converts a v7 UUID into its v4 public form. An error is returned if the UUID is not a v7 UUID
+// with the RFC 4122 variant, as the variant bits would not survive the round-trip.
+func (m *TimestampMasker) Mask(u UUID) (UUID, error) {
+	if len(u) != 16 || u.Version() != 7 || u[8]&0xc0 != 0x80 {
+		return nil, fmt.Errorf("uuid.TimestampMasker.Mask: %x is not a v7 UUID with the RFC 4122 variant", []byte(u))
+	}
+	return m.convert(u, 4), nil
+}
+
+// Unmask converts the v4 public form produced by Mask back into the original v7 UUID.
+// An error is returned if the UUID is not a v4 UUID with the RFC 4122 variant.
+func (m *TimestampMasker) Unmask(u UUID) (UUID, error) {
+	if len(u) != 16 || u.Version() != 4 || u[8]&0xc0 != 0x80 {
+		return nil, fmt.Errorf("uuid.TimestampMasker.Unmask: %x is not a v4 UUID with the RFC 4122 variant", []byte(u))
+	}
+	return m.convert(u, 7), nil
+}
+
+// convert XORs the timestamp with the mask derived from the random bits, and sets the version.
+func (m *TimestampMasker) convert(u UUID, version byte) UUID {
+	// the 74 random bits: low nibble of byte 6, byte 7, low 6 bits of byte 8, bytes 9-15
+	var msg [10]byte
+	msg[0] = u[6] & 0x0f
+	msg[1] = u[7]
+	msg[2] = u[8] & 0x3f
+	copy(msg[3:], u[9:16])
+	mask := sipHash24(m.k0, m.k1, msg[:])
+
+	out := make([]byte, 16)
+	copy(out, u)
+	for i := 0; i < 6; i++ {
+		out[i] ^= byte(mask >> (40 - 8*uint(i)))
+	}
+	out[6] = out[6]&0x0f | version<<4
+
+	return UUID(out)
+}
+
+// sipHash24 computes the SipHash-2-4 of the message with the key (k0, k1).
+func sipHash24(k0, k1 uint64, msg []byte) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = v1<<13 | v1>>51
+		v1 ^= v0
+		v0 = v0<<32 | v0>>32
+		v2 += v3
+		v3 = v3<<16 | v3>>48
+		v3 ^= v2
+		v0 += v3
+		v3 = v3<<21 | v3>>43
+		v3 ^= v0
+		v2 += v1
+		v1 = v1<<17 | v1>>47
+		v1 ^= v2
+		v2 = v2<<32 | v2>>32
+	}
+
+	n := len(msg)
+	for len(msg) >= 8 {
+		m := binary.LittleEndian.Uint64(msg)
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+		msg = msg[8:]
+	}
+	var last [8]byte
+	copy(last[:], msg)
+	last[7] = byte(n)
+	m := binary.LittleEndian.Uint64(last[:])
+	v3 ^= m
+	round()
+	round()
+	v0 ^= m
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
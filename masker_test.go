@@ -0,0 +1,149 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSipHash24(t *testing.T) {
+	// test vectors from the SipHash reference implementation: key 00..0f, message 00..(n-1)
+	tcs := []struct {
+		n   int
+		out uint64
+	}{
+		{0, 0x726fdb47dd0e0e31},
+		{1, 0x74f839c593dc67fd},
+		{8, 0x93f5f5799a932462},
+		{15, 0xa129ca6149be45e5},
+	}
+	k0, k1 := uint64(0x0706050403020100), uint64(0x0f0e0d0c0b0a0908)
+	for _, tc := range tcs {
+		msg := make([]byte, tc.n)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+		if act := sipHash24(k0, k1, msg); act != tc.out {
+			t.Errorf("TestSipHash24[%d]: got %016x want %016x", tc.n, act, tc.out)
+		}
+	}
+}
+
+func TestTimestampMasker(t *testing.T) {
+	if _, err := NewTimestampMasker([]byte("short key")); err == nil {
+		t.Error("TestTimestampMasker: should fail on short key")
+	}
+	m, err := NewTimestampMasker([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal("TestTimestampMasker:", err)
+	}
+
+	// regression vectors, produced by this implementation
+	tcs := []struct {
+		v7 string
+		v4 string
+	}{
+		{"01890a5d-ac96-774b-bcce-b302099a8057", "ca9d6ef1-aca7-474b-bcce-b302099a8057"},
+		{"0188e8d4-7c62-7000-8000-000000000000", "12c8c99e-d69c-4000-8000-000000000000"},
+		{"018f2d9f-9a2a-7def-8c3f-7b1a2c4d9e10", "3326071d-b494-4def-8c3f-7b1a2c4d9e10"},
+	}
+	for _, tc := range tcs {
+		v7, _ := NewFromString(tc.v7)
+		v4, err := m.Mask(v7)
+		if err != nil {
+			t.Errorf("TestTimestampMasker(%s): %s", tc.v7, err)
+		} else if v4.String() != tc.v4 {
+			t.Errorf("TestTimestampMasker(%s): got %s want %s", tc.v7, v4, tc.v4)
+		}
+		v4, _ = NewFromString(tc.v4)
+		v7, err = m.Unmask(v4)
+		if err != nil {
+			t.Errorf("TestTimestampMasker(%s): %s", tc.v4, err)
+		} else if v7.String() != tc.v7 {
+			t.Errorf("TestTimestampMasker(%s): got %s want %s", tc.v4, v7, tc.v7)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		v7 := NewV7()
+		v4, err := m.Mask(v7)
+		if err != nil {
+			t.Fatal("TestTimestampMasker:", err)
+		}
+		if v4.Version() != 4 || v4.Variant()>>1 != 2 {
+			t.Fatalf("TestTimestampMasker: %s is not a valid v4 UUID", v4)
+		}
+		act, err := m.Unmask(v4)
+		if err != nil {
+			t.Fatal("TestTimestampMasker:", err)
+		}
+		if !bytes.Equal(act, v7) {
+			t.Fatalf("TestTimestampMasker: round-trip got %s want %s", act, v7)
+		}
+	}
+
+	if _, err = m.Mask(New()); err == nil {
+		t.Error("TestTimestampMasker: Mask should fail on v1 UUID")
+	}
+	if _, err = m.Unmask(NewV7()); err == nil {
+		t.Error("TestTimestampMasker: Unmask should fail on v7 UUID")
+	}
+
+	// variants other than RFC 4122, such as the one of this package, would not round-trip
+	for _, variant := range []byte{0x20, 0x00, 0xc0, 0xe0} {
+		v7 := NewV7()
+		v7[8] = v7[8]&0x1f | variant
+		if v4, err := m.Mask(v7); err == nil {
+			t.Errorf("TestTimestampMasker: Mask should fail on variant %02x, got %s", variant, v4)
+		}
+		v4, _ := m.Mask(NewV7())
+		v4[8] = v4[8]&0x1f | variant
+		if v7, err := m.Unmask(v4); err == nil {
+			t.Errorf("TestTimestampMasker: Unmask should fail on variant %02x, got %s", variant, v7)
+		}
+	}
+}
+
+func TestTimestampMaskerConstruction(t *testing.T) {
+	// the key of the UUIDv47 reference tests, as (k0, k1) in little-endian order
+	key := make([]byte, 16)
+	binary.LittleEndian.PutUint64(key, 0x0123456789abcdef)
+	binary.LittleEndian.PutUint64(key[8:], 0xfedcba9876543210)
+	m, _ := NewTimestampMasker(key)
+
+	for i := uint64(0); i < 16; i++ {
+		// v7 from its fields: 48-bit timestamp, 12-bit rand_a, 62-bit rand_b
+		ts, randA, randB := 0x10000000*i+123, 0x0aaa^uint16(i*0x111)&0x0fff, (0x0123456789abcdef^0x1111111111111111*i)&(1<<62-1)
+		v7 := make(UUID, 16)
+		binary.BigEndian.PutUint64(v7[0:], ts<<16|7<<12|uint64(randA))
+		binary.BigEndian.PutUint64(v7[8:], 2<<62|randB)
+
+		// SipHash-2-4 over the 10 bytes holding the 74 random bits, in UUID byte order
+		msg := append([]byte{byte(randA >> 8), byte(randA), byte(randB >> 56)}, v7[9:]...)
+		mask := sipHash24(0x0123456789abcdef, 0xfedcba9876543210, msg) & (1<<48 - 1)
+		want := make(UUID, 16)
+		binary.BigEndian.PutUint64(want[0:], (ts^mask)<<16|4<<12|uint64(randA))
+		copy(want[8:], v7[8:])
+
+		if v4, err := m.Mask(v7); err != nil || !bytes.Equal(v4, want) {
+			t.Errorf("TestTimestampMaskerConstruction(%s): got %s, %v want %s", v7, v4, err, want)
+		}
+		if act, err := m.Unmask(want); err != nil || !bytes.Equal(act, v7) {
+			t.Errorf("TestTimestampMaskerConstruction(%s): got %s, %v want %s", want, act, err, v7)
+		}
+	}
+}
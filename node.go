@@ -0,0 +1,153 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NodeProvider provides the node identifier of a Generator, see GeneratorConfig.Node.
+type NodeProvider interface {
+	// Node returns either a 30-bit node id, as accepted by SetNodeId, or, if mac is true,
+	// a 48-bit IEEE 802 MAC address.
+	Node() (node uint64, mac bool, err error)
+}
+
+// nodeProviderFunc adapts a function to the NodeProvider interface.
+type nodeProviderFunc func() (uint64, bool, error)
+
+func (f nodeProviderFunc) Node() (uint64, bool, error) {
+	return f()
+}
+
+// aliases to allow mocking in tests
+var (
+	netInterfaces = net.Interfaces
+	osHostname    = os.Hostname
+)
+
+// NodeFromId provides a fixed 30-bit node id.
+func NodeFromId(nodeId uint32) NodeProvider {
+	return nodeProviderFunc(func() (uint64, bool, error) {
+		return uint64(nodeId), false, nil
+	})
+}
+
+// NodeFromMAC provides the hardware address of the named network interface, or, if name is empty,
+// of the first interface that is up, is not a loopback and has a unicast 48-bit address.
+// The address is used as is, switching the generator to the classic mode of RFC 4122.
+// Only one generator on the host may use the address, see GeneratorConfig.Node.
+func NodeFromMAC(name string) NodeProvider {
+	return nodeProviderFunc(func() (uint64, bool, error) {
+		ifaces, err := netInterfaces()
+		if err != nil {
+			return 0, false, fmt.Errorf("uuid.NodeFromMAC: %v", err)
+		}
+		for _, iface := range ifaces {
+			if name != "" && iface.Name != name {
+				continue
+			}
+			addr := iface.HardwareAddr
+			if len(addr) != 6 || addr[0]&0x01 != 0 || macIsZero(addr) {
+				if name != "" {
+					return 0, false, fmt.Errorf("uuid.NodeFromMAC: interface %s has no unicast 48-bit address", name)
+				}
+				continue
+			}
+			if name == "" && (iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0) {
+				continue
+			}
+			node := uint64(0)
+			for _, b := range addr {
+				node = node<<8 | uint64(b)
+			}
+			return node, true, nil
+		}
+		if name != "" {
+			return 0, false, fmt.Errorf("uuid.NodeFromMAC: no interface named %s", name)
+		}
+		return 0, false, fmt.Errorf("uuid.NodeFromMAC: no suitable network interface")
+	})
+}
+
+// NodeFromHostname provides a 30-bit node id derived from the FNV-1a hash of the host name.
+// Distinct host names may collide, so the node id is only likely, not guaranteed, to be unique.
+func NodeFromHostname() NodeProvider {
+	return nodeProviderFunc(func() (uint64, bool, error) {
+		hostname, err := osHostname()
+		if err != nil {
+			return 0, false, fmt.Errorf("uuid.NodeFromHostname: %v", err)
+		}
+		h := fnv.New32a()
+		h.Write([]byte(hostname))
+		sum := h.Sum32()
+		// xor-fold to 30 bits
+		return uint64((sum>>30 ^ sum) & 0x3fffffff), false, nil
+	})
+}
+
+// NodeFromEnv provides the 30-bit node id set in the named environment variable,
+// in decimal, or in hexadecimal or octal with a "0x" or "0" prefix.
+func NodeFromEnv(name string) NodeProvider {
+	return nodeProviderFunc(func() (uint64, bool, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return 0, false, fmt.Errorf("uuid.NodeFromEnv: environment variable %s is not set", name)
+		}
+		nodeId, err := strconv.ParseUint(strings.TrimSpace(value), 0, 30)
+		if err != nil {
+			return 0, false, fmt.Errorf("uuid.NodeFromEnv: %v", err)
+		}
+		return nodeId, false, nil
+	})
+}
+
+// NodeFromStatefulSet provides the ordinal of a Kubernetes StatefulSet pod as node id,
+// parsed from the pod name (e.g. 3 for "web-3"). If podName is empty, the host name is used,
+// which Kubernetes sets to the pod name.
+func NodeFromStatefulSet(podName string) NodeProvider {
+	return nodeProviderFunc(func() (uint64, bool, error) {
+		if podName == "" {
+			var err error
+			if podName, err = osHostname(); err != nil {
+				return 0, false, fmt.Errorf("uuid.NodeFromStatefulSet: %v", err)
+			}
+		}
+		i := strings.LastIndexByte(podName, '-')
+		if i < 0 {
+			return 0, false, fmt.Errorf("uuid.NodeFromStatefulSet: %s is not a StatefulSet pod name", podName)
+		}
+		ordinal, err := strconv.ParseUint(podName[i+1:], 10, 30)
+		if err != nil {
+			return 0, false, fmt.Errorf("uuid.NodeFromStatefulSet: %s is not a StatefulSet pod name", podName)
+		}
+		return ordinal, false, nil
+	})
+}
+
+// macIsZero reports whether all the bytes of the address are zero.
+func macIsZero(addr net.HardwareAddr) bool {
+	for _, b := range addr {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
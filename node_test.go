@@ -0,0 +1,127 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"net"
+	"os"
+	"testing"
+)
+
+func TestNodeFromMAC(t *testing.T) {
+	netInterfaces = func() ([]net.Interface, error) {
+		return []net.Interface{
+			{Name: "lo", Flags: net.FlagUp | net.FlagLoopback, HardwareAddr: net.HardwareAddr{0, 0, 0, 0, 0, 1}},
+			{Name: "down0", HardwareAddr: net.HardwareAddr{0x0a, 0, 0, 0, 0, 2}},
+			{Name: "zero0", Flags: net.FlagUp},
+			{Name: "mcast0", Flags: net.FlagUp, HardwareAddr: net.HardwareAddr{0x01, 0, 0, 0, 0, 3}},
+			{Name: "eth0", Flags: net.FlagUp, HardwareAddr: net.HardwareAddr{0x0a, 0x1b, 0x2c, 0x3d, 0x4e, 0x5f}},
+		}, nil
+	}
+	defer func() { netInterfaces = net.Interfaces }()
+
+	for _, tc := range []struct {
+		name string
+		node uint64
+	}{
+		{"", 0x0a1b2c3d4e5f},
+		{"eth0", 0x0a1b2c3d4e5f},
+		{"down0", 0x0a0000000002},
+	} {
+		node, mac, err := NodeFromMAC(tc.name).Node()
+		if err != nil {
+			t.Errorf("TestNodeFromMAC(%q): %v", tc.name, err)
+		} else if !mac || node != tc.node {
+			t.Errorf("TestNodeFromMAC(%q): Expecting %012x, true, got %012x, %t", tc.name, tc.node, node, mac)
+		}
+	}
+	for _, name := range []string{"mcast0", "zero0", "wlan0"} {
+		if _, _, err := NodeFromMAC(name).Node(); err == nil {
+			t.Errorf("TestNodeFromMAC(%q): expecting error, got nil", name)
+		}
+	}
+}
+
+func TestNodeFromHostname(t *testing.T) {
+	osHostname = func() (string, error) { return "web-3", nil }
+	defer func() { osHostname = os.Hostname }()
+
+	node, mac, err := NodeFromHostname().Node()
+	if err != nil {
+		t.Fatal("TestNodeFromHostname:", err)
+	}
+	// FNV-1a("web-3") = 0x78b7ba45
+	if want := uint64(0x78b7ba45>>30^0x78b7ba45) & 0x3fffffff; mac || node != want {
+		t.Errorf("TestNodeFromHostname: Expecting %x, false, got %x, %t", want, node, mac)
+	}
+	if node>>30 != 0 {
+		t.Errorf("TestNodeFromHostname: %x does not fit in 30 bits", node)
+	}
+
+	node, _, err = NodeFromStatefulSet("").Node()
+	if err != nil || node != 3 {
+		t.Errorf("TestNodeFromStatefulSet(hostname): Expecting 3, got %d, %v", node, err)
+	}
+}
+
+func TestNodeFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		node  uint64
+		ok    bool
+	}{
+		{"42", 42, true},
+		{" 0x2a\n", 42, true},
+		{"0x3fffffff", 0x3fffffff, true},
+		{"0x40000000", 0, false},
+		{"-1", 0, false},
+		{"node1", 0, false},
+	} {
+		t.Setenv("UUID_NODE_ID", tc.value)
+		node, mac, err := NodeFromEnv("UUID_NODE_ID").Node()
+		if tc.ok && (err != nil || mac || node != tc.node) {
+			t.Errorf("TestNodeFromEnv(%q): Expecting %x, got %x, %v", tc.value, tc.node, node, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("TestNodeFromEnv(%q): expecting error, got nil", tc.value)
+		}
+	}
+	if _, _, err := NodeFromEnv("UUID_NODE_ID_UNSET").Node(); err == nil {
+		t.Error("TestNodeFromEnv(unset): expecting error, got nil")
+	}
+}
+
+func TestNodeFromStatefulSet(t *testing.T) {
+	for _, tc := range []struct {
+		pod  string
+		node uint64
+		ok   bool
+	}{
+		{"web-0", 0, true},
+		{"my-app-db-12", 12, true},
+		{"web", 0, false},
+		{"web-", 0, false},
+		{"web-abc", 0, false},
+		{"web-1073741824", 0, false},
+	} {
+		node, mac, err := NodeFromStatefulSet(tc.pod).Node()
+		if tc.ok && (err != nil || mac || node != tc.node) {
+			t.Errorf("TestNodeFromStatefulSet(%q): Expecting %d, got %d, %v", tc.pod, tc.node, node, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("TestNodeFromStatefulSet(%q): expecting error, got nil", tc.pod)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+const (
+	obfuscatorRounds = 8
+	obfuscatorMask   = 1<<59 - 1
+)
+
+// Obfuscator reversibly encrypts v1 UUIDs generated by New or NewCrypto into UUIDs that look like
+// random (v4) UUIDs, hiding the embedded time and node id, so that internal ids can be exposed publicly.
+//
+// A v1 UUID generated by this package carries 118 variable bits: 59 bits of timestamp (the most
+// significant bit stays zero until the year 3400), 13 bits of clock sequence and 46 bits of node
+// identifier. These are encrypted with a balanced Feistel network using AES as round function,
+// and stored in a v4 UUID together with the 4-bit id of the key used, so that keys can be rotated
+// while UUIDs encrypted with previous keys can still be decrypted.
+//
+// An Obfuscator is safe for concurrent use.
+type Obfuscator struct {
+	mutex   sync.RWMutex
+	keys    [16]cipher.Block
+	current uint8
+}
+
+// NewObfuscator creates an Obfuscator that encrypts using the provided key with the provided id.
+// The key id must be less than 16 and the key must be 16, 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256.
+func NewObfuscator(keyId uint8, key []byte) (*Obfuscator, error) {
+	o := &Obfuscator{}
+	if err := o.Rotate(keyId, key); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// AddKey adds a key that is only used for decrypting UUIDs, such as a retired key.
+func (o *Obfuscator) AddKey(keyId uint8, key []byte) error {
+	block, err := obfuscatorCipher(keyId, key)
+	if err != nil {
+		return fmt.Errorf("uuid.Obfuscator.AddKey: %v", err)
+	}
+	o.mutex.Lock()
+	o.keys[keyId] = block
+	o.mutex.Unlock()
+	return nil
+}
+
+// Rotate adds a key and makes it the one used for encrypting UUIDs.
+func (o *Obfuscator) Rotate(keyId uint8, key []byte) error {
+	block, err := obfuscatorCipher(keyId, key)
+	if err != nil {
+		return fmt.Errorf("uuid.Obfuscator.Rotate: %v", err)
+	}
+	o.mutex.Lock()
+	o.keys[keyId] = block
+	o.current = keyId
+	o.mutex.Unlock()
+	return nil
+}
+
+// RemoveKey removes a key that is no longer current, so that UUIDs encrypted with it are rejected.
+func (o *Obfuscator) RemoveKey(keyId uint8) error {
+	if keyId >= 16 {
+		return fmt.Errorf("uuid.Obfuscator.RemoveKey: key id %d is out of range (must be less than 16)", keyId)
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if keyId == o.current {
+		return fmt.Errorf("uuid.Obfuscator.RemoveKey: cannot remove the current key %d", keyId)
+	}
+	o.keys[keyId] = nil
+	return nil
+}
+
+// Encrypt encrypts a v1 UUID generated by this package into a v4 UUID.
+func (o *Obfuscator) Encrypt(u UUID) (UUID, error) {
+	if len(u) != 16 || u.Version() != 1 || u[8]&0xe0 != 0x20 || u[10]&0x03 != 0x03 || u[6]&0x08 != 0 {
+		return nil, fmt.Errorf("uuid.Obfuscator.Encrypt: %x is not a v1 UUID generated by this package", []byte(u))
+	}
+	hi := binary.BigEndian.Uint64(u[0:8])
+	lo := binary.BigEndian.Uint64(u[8:16])
+	// timestamp (59 bits)
+	l := (hi&0x07ff)<<48 | (hi>>16&0xffff)<<32 | hi>>32
+	// clock sequence (13 bits) and node without the 'local' and 'multicast' bits (46 bits)
+	r := (lo>>48&0x1fff)<<46 | (lo>>42&0x3f)<<40 | lo&0xffffffffff
+
+	o.mutex.RLock()
+	keyId, block := o.current, o.keys[o.current]
+	o.mutex.RUnlock()
+
+	for i := 0; i < obfuscatorRounds; i++ {
+		l, r = r, l^obfuscatorRound(block, i, r)
+	}
+
+	// key id (4 bits) and 56 bits of the left half go in the 60 free bits of the first 8 bytes,
+	// the remaining 3 bits of the left half and the right half in the 62 free bits of the last 8 bytes
+	a := uint64(keyId)<<56 | l>>3
+	hi = (a>>12)<<16 | /*version*/ 4<<12 | a&0x0fff
+	lo = /*variant*/ 1<<63 | (l&0x07)<<59 | r
+	out := make([]byte, 16)
+	binary.BigEndian.PutUint64(out[0:8], hi)
+	binary.BigEndian.PutUint64(out[8:16], lo)
+
+	return UUID(out), nil
+}
+
+// Decrypt decrypts a v4 UUID produced by Encrypt back into the original v1 UUID.
+func (o *Obfuscator) Decrypt(u UUID) (UUID, error) {
+	if len(u) != 16 || u.Version() != 4 || u[8]&0xc0 != 0x80 {
+		return nil, fmt.Errorf("uuid.Obfuscator.Decrypt: %x is not a v4 UUID", []byte(u))
+	}
+	hi := binary.BigEndian.Uint64(u[0:8])
+	lo := binary.BigEndian.Uint64(u[8:16])
+	a := (hi>>16)<<12 | hi&0x0fff
+	keyId := uint8(a >> 56)
+	l := (a&(1<<56-1))<<3 | (lo>>59)&0x07
+	r := lo & obfuscatorMask
+
+	o.mutex.RLock()
+	block := o.keys[keyId]
+	o.mutex.RUnlock()
+	if block == nil {
+		return nil, fmt.Errorf("uuid.Obfuscator.Decrypt: unknown key id %d", keyId)
+	}
+
+	for i := obfuscatorRounds - 1; i >= 0; i-- {
+		l, r = r^obfuscatorRound(block, i, l), l
+	}
+
+	hi = (l&0xffffffff)<<32 | (l>>32&0xffff)<<16 | /*version*/ 1<<12 | l>>48
+	lo = /*variant*/ 1<<61 | (r>>46)<<48 | (r>>40&0x3f)<<42 | 0x03<<40 | r&0xffffffffff
+	out := make([]byte, 16)
+	binary.BigEndian.PutUint64(out[0:8], hi)
+	binary.BigEndian.PutUint64(out[8:16], lo)
+
+	return UUID(out), nil
+}
+
+// obfuscatorRound computes the Feistel round function for round i over the 59-bit half x.
+func obfuscatorRound(block cipher.Block, i int, x uint64) uint64 {
+	var buf [aes.BlockSize]byte
+	buf[0] = byte(i)
+	binary.BigEndian.PutUint64(buf[8:], x)
+	block.Encrypt(buf[:], buf[:])
+	return binary.BigEndian.Uint64(buf[:8]) & obfuscatorMask
+}
+
+// obfuscatorCipher validates the key id and creates the AES cipher for the key.
+func obfuscatorCipher(keyId uint8, key []byte) (cipher.Block, error) {
+	if keyId >= 16 {
+		return nil, fmt.Errorf("key id %d is out of range (must be less than 16)", keyId)
+	}
+	return aes.NewCipher(key)
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"testing"
+)
+
+var (
+	obfuscatorKey1 = []byte("0123456789abcdef")
+	obfuscatorKey2 = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestObfuscator(t *testing.T) {
+	if _, err := NewObfuscator(16, obfuscatorKey1); err == nil {
+		t.Error("TestObfuscator: should fail on key id out of range")
+	}
+	if _, err := NewObfuscator(1, obfuscatorKey1[1:]); err == nil {
+		t.Error("TestObfuscator: should fail on invalid key length")
+	}
+
+	o, err := NewObfuscator(1, obfuscatorKey1)
+	if err != nil {
+		t.Fatal("TestObfuscator:", err)
+	}
+	for i := 0; i < 1000; i++ {
+		var uuid1 UUID
+		if i%2 == 0 {
+			uuid1 = New()
+		} else {
+			uuid1 = NewCrypto()
+		}
+		enc, err := o.Encrypt(uuid1)
+		if err != nil {
+			t.Fatal("TestObfuscator:", err)
+		}
+		if enc.Version() != 4 || enc.Variant()>>1 != 2 {
+			t.Fatalf("TestObfuscator: %s does not look like a v4 UUID", enc)
+		}
+		if enc.NodeId() == uuid1.NodeId() && bytes.Equal(enc[:4], uuid1[:4]) {
+			t.Fatalf("TestObfuscator: %s leaks details of %s", enc, uuid1)
+		}
+		dec, err := o.Decrypt(enc)
+		if err != nil {
+			t.Fatal("TestObfuscator:", err)
+		}
+		if !bytes.Equal(dec, uuid1) {
+			t.Fatalf("TestObfuscator: round-trip got %s want %s", dec, uuid1)
+		}
+	}
+
+	if _, err = o.Encrypt(NewV7()); err == nil {
+		t.Error("TestObfuscator: Encrypt should fail on v7 UUID")
+	}
+	if _, err = o.Decrypt(New()); err == nil {
+		t.Error("TestObfuscator: Decrypt should fail on v1 UUID")
+	}
+}
+
+func TestObfuscatorRotate(t *testing.T) {
+	o, _ := NewObfuscator(1, obfuscatorKey1)
+	uuid1 := New()
+	enc1, _ := o.Encrypt(uuid1)
+
+	if err := o.Rotate(2, obfuscatorKey2); err != nil {
+		t.Fatal("TestObfuscatorRotate:", err)
+	}
+	enc2, _ := o.Encrypt(uuid1)
+	if bytes.Equal(enc1, enc2) {
+		t.Error("TestObfuscatorRotate: expecting different encryption after rotation")
+	}
+	for _, enc := range []UUID{enc1, enc2} {
+		dec, err := o.Decrypt(enc)
+		if err != nil {
+			t.Error("TestObfuscatorRotate:", err)
+		} else if !bytes.Equal(dec, uuid1) {
+			t.Errorf("TestObfuscatorRotate: got %s want %s", dec, uuid1)
+		}
+	}
+
+	if err := o.RemoveKey(2); err == nil {
+		t.Error("TestObfuscatorRotate: should fail to remove current key")
+	}
+	if err := o.RemoveKey(16); err == nil {
+		t.Error("TestObfuscatorRotate: should fail to remove out of range key id")
+	}
+	if err := o.RemoveKey(1); err != nil {
+		t.Error("TestObfuscatorRotate:", err)
+	}
+	if _, err := o.Decrypt(enc1); err == nil {
+		t.Error("TestObfuscatorRotate: should fail to decrypt with removed key")
+	}
+
+	o2, _ := NewObfuscator(2, obfuscatorKey2)
+	if err := o2.AddKey(1, obfuscatorKey1); err != nil {
+		t.Fatal("TestObfuscatorRotate:", err)
+	}
+	if dec, err := o2.Decrypt(enc1); err != nil || !bytes.Equal(dec, uuid1) {
+		t.Errorf("TestObfuscatorRotate: AddKey decryption got %s, %v want %s", dec, err, uuid1)
+	}
+	if enc, _ := o2.Encrypt(uuid1); !bytes.Equal(enc, enc2) {
+		t.Errorf("TestObfuscatorRotate: expecting %s, got %s", enc2, enc)
+	}
+}
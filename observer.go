@@ -0,0 +1,84 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"expvar"
+	"strconv"
+	"time"
+)
+
+// Observer is notified of the activity of a Generator, see GeneratorConfig.Observer.
+// Its methods are called concurrently, sometimes while holding a generator lock, so they must return quickly.
+type Observer interface {
+	// Generated is called when n UUIDs of the version are generated.
+	Generated(version, n int)
+	// Rollover is called when the clock sequence of New rolls over, regenerating the last 16 bits of the node identifier.
+	Rollover()
+	// ClockRegression is called when the clock went backwards by d since the previous UUID generated by the same shard.
+	// It is not called in the classic mode of RFC 4122, where the timestamps are kept increasing.
+	ClockRegression(d time.Duration)
+	// EntropyFailure is called when the cryptographic random source, or its health test, fails;
+	// the failure is then handled according to the entropy policy.
+	EntropyFailure(err error)
+	// LockWait is called with the time spent waiting for a contended generator lock.
+	LockWait(d time.Duration)
+}
+
+// ExpvarObserver is an Observer publishing the counters of a Generator as an expvar.Map, with the keys:
+//
+//	generated_v1, generated_v7  number of UUIDs generated per version
+//	rollovers                   number of clock sequence rollovers
+//	clock_regressions           number of clock regressions
+//	clock_regression_ns         total duration of the clock regressions, in nanoseconds
+//	entropy_failures            number of random source failures
+//	lock_waits                  number of waits for a contended lock
+//	lock_wait_ns                total duration of the waits, in nanoseconds
+type ExpvarObserver struct {
+	Map *expvar.Map
+}
+
+// NewExpvarObserver creates an ExpvarObserver publishing its counters under the name.
+// Like expvar.NewMap, it panics if the name is already registered.
+func NewExpvarObserver(name string) *ExpvarObserver {
+	return &ExpvarObserver{expvar.NewMap(name)}
+}
+
+// Generated implements the Observer interface.
+func (o *ExpvarObserver) Generated(version, n int) {
+	o.Map.Add("generated_v"+strconv.Itoa(version), int64(n))
+}
+
+// Rollover implements the Observer interface.
+func (o *ExpvarObserver) Rollover() {
+	o.Map.Add("rollovers", 1)
+}
+
+// ClockRegression implements the Observer interface.
+func (o *ExpvarObserver) ClockRegression(d time.Duration) {
+	o.Map.Add("clock_regressions", 1)
+	o.Map.Add("clock_regression_ns", int64(d))
+}
+
+// EntropyFailure implements the Observer interface.
+func (o *ExpvarObserver) EntropyFailure(err error) {
+	o.Map.Add("entropy_failures", 1)
+}
+
+// LockWait implements the Observer interface.
+func (o *ExpvarObserver) LockWait(d time.Duration) {
+	o.Map.Add("lock_waits", 1)
+	o.Map.Add("lock_wait_ns", int64(d))
+}
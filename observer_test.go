@@ -0,0 +1,120 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"crypto/rand"
+	"expvar"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// singleShardGenerator creates a generator with a single shard, as on a single CPU.
+func singleShardGenerator(t testing.TB, config GeneratorConfig) *Generator {
+	procs := runtime.GOMAXPROCS(1)
+	g, err := NewGenerator(config)
+	runtime.GOMAXPROCS(procs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestExpvarObserver(t *testing.T) {
+	// unique name, as expvar names cannot be reused
+	name := fmt.Sprintf("uuid_test_observer_%d", time.Now().UnixNano())
+	o := NewExpvarObserver(name)
+	if expvar.Get(name) != o.Map {
+		t.Fatal("TestExpvarObserver: map not published")
+	}
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	defer func() { randReader = rand.Reader }()
+
+	g := singleShardGenerator(t, GeneratorConfig{Observer: o})
+	// rolls the 13-bit clock sequence over once
+	g.shards[0].clockSeq = 0
+	for i := 0; i < 0x2000; i++ {
+		g.New()
+	}
+	g.NewBatch(10)
+	g.NewCrypto()
+	g.NewV7()
+	g.NewV7()
+
+	// the clock goes back one second
+	timeNow = func() time.Time { return now.Add(-time.Second) }
+	g.New()
+
+	randReader = &flakyReader{failures: -1}
+	g.shards[0].randBufOffset = randBufCap
+	g.NewCrypto()
+
+	// a contended lock
+	g.shards[0].mutex.Lock()
+	done := make(chan struct{})
+	go func() {
+		g.New()
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	g.shards[0].mutex.Unlock()
+	<-done
+
+	for key, want := range map[string]int64{
+		"generated_v1":        0x2000 + 10 + 1 + 1 + 1 + 1,
+		"generated_v7":        2,
+		"rollovers":           1,
+		"clock_regressions":   1,
+		"clock_regression_ns": int64(time.Second),
+		"entropy_failures":    1,
+		"lock_waits":          1,
+	} {
+		v, ok := o.Map.Get(key).(*expvar.Int)
+		if !ok {
+			t.Errorf("TestExpvarObserver: missing %s", key)
+		} else if v.Value() != want {
+			t.Errorf("TestExpvarObserver: Expecting %s %d, got %d", key, want, v.Value())
+		}
+	}
+	if v, _ := o.Map.Get("lock_wait_ns").(*expvar.Int); v == nil || v.Value() < int64(10*time.Millisecond) {
+		t.Errorf("TestExpvarObserver: Expecting lock_wait_ns of at least 10ms, got %v", v)
+	}
+}
+
+func TestDefaultGeneratorObserver(t *testing.T) {
+	o := &ExpvarObserver{Map: new(expvar.Map)}
+	g := singleShardGenerator(t, GeneratorConfig{Observer: o})
+	setDefaultGenerator(t, g)
+	// rolls the 13-bit clock sequence over once
+	g.shards[0].clockSeq = 0x1fff
+
+	New()
+	NewCrypto()
+	NewBatch(3)
+	NewV7()
+	for key, want := range map[string]int64{
+		"generated_v1": 1 + 1 + 3,
+		"generated_v7": 1,
+		"rollovers":    1,
+	} {
+		if v, ok := o.Map.Get(key).(*expvar.Int); !ok || v.Value() != want {
+			t.Errorf("TestDefaultGeneratorObserver: Expecting %s %d, got %v", key, want, o.Map.Get(key))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrSignature is returned when verifying a well-formed token whose signature does not match any of the keys.
+var ErrSignature = errors.New("uuid: signature mismatch")
+
+// DefaultSigner signs with a 96-bit truncated HMAC-SHA256, encoding the token with strict unpadded Base64 URL Encoding
+// (38 characters for a UUID), which rejects non-zero unused bits in the last character, so that tokens are canonical.
+var DefaultSigner = Signer{strictBase64URL, strictBase64URL, 12}
+
+var strictBase64URL = Base64Encoder{base64.RawURLEncoding.Strict()}
+
+// Signer creates and verifies tokens made of a UUID followed by its HMAC-SHA256 truncated to Size bytes,
+// encoded with Enc and decoded with Dec.
+type Signer struct {
+	Enc  EncoderToString
+	Dec  DecoderFromString
+	Size int
+}
+
+// Sign creates a token for the UUID using the provided key. An error is returned if the UUID is not 16 bytes long.
+func (s Signer) Sign(u UUID, key []byte) (string, error) {
+	if len(u) != 16 {
+		return "", fmt.Errorf("uuid.Signer.Sign: UUID length is wrong (%d instead of 16)", len(u))
+	}
+	b := make([]byte, 0, 16+s.size())
+	b = append(b, u...)
+	return s.Enc.EncodeToString(append(b, s.mac(u, key)...)), nil
+}
+
+// Verify decodes the token and checks its signature against each of the provided keys in turn,
+// returning the UUID if any of them matches. This allows rotating keys by signing with the newest one
+// while still accepting tokens signed with previous ones. Malformed tokens produce a decoding error,
+// while well-formed tokens that do not match any key produce ErrSignature.
+func (s Signer) Verify(token string, keys ...[]byte) (UUID, error) {
+	b, err := s.Dec.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 16+s.size() {
+		return nil, fmt.Errorf("uuid.Signer.Verify: Decoded length is wrong (%d instead of %d)", len(b), 16+s.size())
+	}
+	u, sig := UUID(b[:16]), b[16:]
+	for _, key := range keys {
+		if hmac.Equal(sig, s.mac(u, key)) {
+			return u, nil
+		}
+	}
+	return nil, ErrSignature
+}
+
+// mac computes the truncated HMAC-SHA256 of the UUID.
+func (s Signer) mac(u UUID, key []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(u)
+	return h.Sum(nil)[:s.size()]
+}
+
+// size returns the signature size, clamped to the range supported by HMAC-SHA256.
+func (s Signer) size() int {
+	if s.Size <= 0 || s.Size > sha256.Size {
+		return sha256.Size
+	}
+	return s.Size
+}
+
+// Sign creates a token for the UUID using the provided key and the DefaultSigner.
+func Sign(u UUID, key []byte) (string, error) {
+	return DefaultSigner.Sign(u, key)
+}
+
+// Verify checks a token created by Sign against each of the provided keys, using the DefaultSigner.
+func Verify(token string, keys ...[]byte) (UUID, error) {
+	return DefaultSigner.Verify(token, keys...)
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	key1, key2 := []byte("first secret"), []byte("second secret")
+	uuid1, _ := NewFromBytes(uuid)
+
+	token, err := Sign(uuid1, key1)
+	if err != nil || len(token) != 38 {
+		t.Errorf("TestSign: expecting 38 characters, got %d in %s", len(token), token)
+	}
+	if token[:21] != uuid1.EncodeToString(Base64URLEncoder)[:21] {
+		t.Errorf("TestSign: expecting %s to start with the encoded UUID", token)
+	}
+
+	act, err := Verify(token, key1)
+	if err != nil {
+		t.Error("TestSign:", err)
+	} else if !bytes.Equal(act, uuid1) {
+		t.Errorf("TestSign: got %s want %s", act, uuid1)
+	}
+	if act, err = Verify(token, key2, key1); err != nil || !bytes.Equal(act, uuid1) {
+		t.Errorf("TestSign: with rotated keys got %s, %v want %s", act, err, uuid1)
+	}
+	if _, err = Verify(token, key2); err != ErrSignature {
+		t.Errorf("TestSign: expecting ErrSignature for wrong key, got %v", err)
+	}
+	if _, err = Verify(token); err != ErrSignature {
+		t.Errorf("TestSign: expecting ErrSignature without keys, got %v", err)
+	}
+
+	forged, _ := Sign(New(), key2)
+	if _, err = Verify(forged[:22]+token[22:], key1); err != ErrSignature {
+		t.Errorf("TestSign: expecting ErrSignature for forged UUID, got %v", err)
+	}
+	if _, err = Verify(token[:30], key1); err == nil || err == ErrSignature {
+		t.Errorf("TestSign: expecting format error for truncated token, got %v", err)
+	}
+	if _, err = Verify(token[:37]+"!", key1); err == nil || err == ErrSignature {
+		t.Errorf("TestSign: expecting format error for invalid character, got %v", err)
+	}
+	// same decoded bytes, with non-zero unused bits in the last character
+	last := strings.IndexByte(Base64URLAlphabet, token[37])
+	if _, err = Verify(token[:37]+Base64URLAlphabet[last^1:last^1+1], key1); err == nil || err == ErrSignature {
+		t.Errorf("TestSign: expecting format error for non-canonical token, got %v", err)
+	}
+	if _, err = Sign(uuid1[:15], key1); err == nil {
+		t.Error("TestSign: expecting error for invalid UUID length")
+	}
+
+	s := Signer{Z85Encoder, Z85Encoder, 0}
+	token, err = s.Sign(uuid1, key1)
+	if err != nil || len(token) != 60 {
+		t.Errorf("TestSign: expecting full-size Z85 signature, got %s", token)
+	}
+	if act, err = s.Verify(token, key1); err != nil || !bytes.Equal(act, uuid1) {
+		t.Errorf("TestSign: custom Signer got %s, %v want %s", act, err, uuid1)
+	}
+}
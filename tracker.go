@@ -0,0 +1,124 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"sync"
+)
+
+// Tracker records recently generated or ingested UUIDs in a pair of rotating Bloom filters,
+// reporting likely duplicates with bounded memory.
+//
+// The current filter records up to capacity UUIDs, then becomes the previous filter, replacing the oldest one,
+// so that a Tracker remembers between capacity and twice capacity of the most recent UUIDs.
+// A UUID not recorded before is reported as a duplicate with the false positive rate of the filters.
+//
+// A Tracker is safe for concurrent use.
+type Tracker struct {
+	mutex      sync.Mutex
+	seeds      [2]maphash.Seed
+	current    []uint64
+	previous   []uint64
+	bits       uint64
+	hashes     int
+	capacity   int
+	count      int
+	duplicates uint64
+}
+
+// NewTracker creates a Tracker remembering at least capacity UUIDs, sizing its filters for the false positive rate.
+func NewTracker(capacity int, falsePositiveRate float64) (*Tracker, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("uuid.NewTracker: capacity %d must be positive", capacity)
+	}
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		return nil, fmt.Errorf("uuid.NewTracker: false positive rate %g must be between 0 and 1", falsePositiveRate)
+	}
+	// the false positive rate applies to the sum of the rates of both filters
+	bits := math.Ceil(-float64(capacity) * math.Log(falsePositiveRate/2) / (math.Ln2 * math.Ln2))
+	words := (uint64(bits) + 63) / 64
+	hashes := int(math.Round(float64(words*64) / float64(capacity) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &Tracker{
+		seeds:    [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()},
+		current:  make([]uint64, words),
+		previous: make([]uint64, words),
+		bits:     words * 64,
+		hashes:   hashes,
+		capacity: capacity,
+	}, nil
+}
+
+// Add records the UUID, reporting whether it was likely recorded before.
+func (t *Tracker) Add(u UUID) bool {
+	h1, h2 := t.hash(u)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.contains(t.current, h1, h2) || t.contains(t.previous, h1, h2) {
+		t.duplicates++
+		return true
+	}
+	if t.count == t.capacity {
+		t.current, t.previous = t.previous, t.current
+		for i := range t.current {
+			t.current[i] = 0
+		}
+		t.count = 0
+	}
+	for i := 0; i < t.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % t.bits
+		t.current[bit/64] |= 1 << (bit % 64)
+	}
+	t.count++
+	return false
+}
+
+// Seen reports whether the UUID was likely recorded, without recording it.
+func (t *Tracker) Seen(u UUID) bool {
+	h1, h2 := t.hash(u)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.contains(t.current, h1, h2) || t.contains(t.previous, h1, h2)
+}
+
+// Duplicates returns the number of likely duplicates reported by Add.
+func (t *Tracker) Duplicates() uint64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.duplicates
+}
+
+// hash returns the two hashes of the UUID used for double hashing; the second one is odd.
+func (t *Tracker) hash(u UUID) (uint64, uint64) {
+	return maphash.Bytes(t.seeds[0], u), maphash.Bytes(t.seeds[1], u) | 1
+}
+
+// contains reports whether all the bits of the hashes are set in the filter.
+func (t *Tracker) contains(filter []uint64, h1, h2 uint64) bool {
+	for i := 0; i < t.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % t.bits
+		if filter[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"flag"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	collisionPeriod = flag.Duration("uuid.collision.period", 0,
+		"run the collision harness for this period (skipped if 0)")
+	collisionGenerators = flag.Int("uuid.collision.generators", 2*runtime.NumCPU(),
+		"number of concurrent generators, sharing the same node id, run by the collision harness")
+)
+
+func TestTracker(t *testing.T) {
+	tr, err := NewTracker(1000, 0.001)
+	if err != nil {
+		t.Fatal("TestTracker:", err)
+	}
+	first := make([]UUID, 1000)
+	for i := range first {
+		first[i] = NewV7()
+		tr.Add(first[i])
+	}
+	// expecting about one false positive
+	if act := tr.Duplicates(); act > 10 {
+		t.Errorf("TestTracker: Expecting few false positives, got %d", act)
+	}
+	before := tr.Duplicates()
+	for _, u := range first {
+		if !tr.Add(u) {
+			t.Fatalf("TestTracker: %s not reported as duplicate", u)
+		}
+	}
+	if act := tr.Duplicates(); act != before+1000 {
+		t.Errorf("TestTracker: Expecting %d duplicates, got %d", before+1000, act)
+	}
+
+	// two rotations forget the first UUIDs
+	for i := 0; i < 2000; i++ {
+		tr.Add(NewCrypto())
+	}
+	forgotten := 0
+	for _, u := range first {
+		if !tr.Seen(u) {
+			forgotten++
+		}
+	}
+	if forgotten < 990 {
+		t.Errorf("TestTracker: Expecting the first UUIDs forgotten, only %d were", forgotten)
+	}
+
+	for _, tc := range []struct {
+		capacity int
+		rate     float64
+	}{
+		{0, 0.01},
+		{10, 0},
+		{10, 1},
+		{10, -0.5},
+	} {
+		if _, err = NewTracker(tc.capacity, tc.rate); err == nil {
+			t.Errorf("TestTracker(%d, %g): expecting error, got nil", tc.capacity, tc.rate)
+		}
+	}
+}
+
+// TestCollisions runs many concurrent generators with the same node id for the period set with
+// -uuid.collision.period, checking that they generate no duplicates, e.g.:
+//
+//	go test -run TestCollisions -uuid.collision.period 1m -uuid.collision.generators 64
+//
+// Across generators, or processes, sharing a node id, only the random clock sequence and
+// last 16 bits of the node identifier tell apart the UUIDs generated in the same 100-nanosecond interval,
+// so the probability of a duplicate grows with the number of generators and the generation rate.
+func TestCollisions(t *testing.T) {
+	if *collisionPeriod == 0 {
+		t.Skip("set -uuid.collision.period to run the collision harness")
+	}
+	for _, tc := range []struct {
+		name     string
+		generate func(g *Generator) UUID
+	}{
+		{"New", (*Generator).New},
+		{"NewCrypto", (*Generator).NewCrypto},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, err := NewTracker(1<<22, 1e-12)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				wg         sync.WaitGroup
+				generated  atomic.Int64
+				duplicates = make(chan UUID, 16)
+			)
+			deadline := time.Now().Add(*collisionPeriod)
+			for i := 0; i < *collisionGenerators; i++ {
+				g, err := NewGenerator(GeneratorConfig{Node: NodeFromId(42)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					n := int64(0)
+					for ; n%1024 != 0 || time.Now().Before(deadline); n++ {
+						if u := tc.generate(g); tr.Add(u) {
+							select {
+							case duplicates <- u:
+							default:
+							}
+						}
+					}
+					generated.Add(n)
+				}()
+			}
+			wg.Wait()
+			close(duplicates)
+
+			t.Logf("%d generators, %d UUIDs", *collisionGenerators, generated.Load())
+			for u := range duplicates {
+				t.Errorf("likely duplicate %s", u)
+			}
+			if n := tr.Duplicates(); n != 0 {
+				t.Errorf("%d likely duplicates", n)
+			}
+		})
+	}
+}
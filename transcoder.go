@@ -0,0 +1,276 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const defaultTranscoderBatchSize = 1024
+
+// LineError reports a malformed input line encountered by a Transcoder.
+type LineError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Transcoder converts streams of UUIDs from one encoding to another, one per line or
+// in one column of delimited lines (such as CSV exports), using parallel workers.
+//
+// Lines are processed in batches, with a bounded number of batches in flight, so memory use
+// does not depend on the input size. The output preserves the order of the input lines.
+type Transcoder struct {
+	// Dec decodes the input UUIDs.
+	Dec DecoderFromString
+	// Enc encodes the output UUIDs.
+	Enc EncoderToString
+	// Column is the 1-based index of the column holding the UUID, or 0 if it takes the whole line.
+	// The other columns are copied unchanged. A UUID enclosed in double quotes keeps its quotes.
+	Column int
+	// Delimiter separates the columns; the default is ','. Quoted delimiters are not supported.
+	Delimiter byte
+	// Header is the number of leading lines, such as a CSV header, that are copied unchanged.
+	Header int
+	// Workers is the number of parallel workers; the default is runtime.NumCPU().
+	Workers int
+	// BatchSize is the number of lines per batch; the default is 1024.
+	BatchSize int
+	// OnError is called in input order for each malformed line. If it returns nil, the line is copied
+	// unchanged and the conversion continues, otherwise the conversion stops with the returned error.
+	// If OnError is not set, the conversion stops with the *LineError.
+	OnError func(*LineError) error
+}
+
+// transcoderBatch holds a range of consecutive input lines and their conversion results.
+type transcoderBatch struct {
+	seq   int
+	first int
+	lines []string
+	out   []string
+	errs  []error
+}
+
+// Transcode converts all the lines read from r, writing the results to w.
+// Empty lines are copied unchanged.
+func (t *Transcoder) Transcode(r io.Reader, w io.Writer) error {
+	workers := t.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	batchSize := t.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultTranscoderBatchSize
+	}
+
+	jobs := make(chan *transcoderBatch, workers)
+	results := make(chan *transcoderBatch, workers)
+	// bounds the number of batches read but not yet written
+	tokens := make(chan struct{}, 2*workers)
+	done := make(chan struct{})
+	var readErr error
+
+	go func() {
+		defer close(jobs)
+		scanner := bufio.NewScanner(r)
+		scanner.Split(scanLinesKeepCR)
+		b := &transcoderBatch{first: 1}
+		line := 0
+		send := func() bool {
+			select {
+			case tokens <- struct{}{}:
+			case <-done:
+				return false
+			}
+			jobs <- b
+			b = &transcoderBatch{seq: b.seq + 1, first: line + 1}
+			return true
+		}
+		for scanner.Scan() {
+			line++
+			b.lines = append(b.lines, scanner.Text())
+			if len(b.lines) == batchSize && !send() {
+				return
+			}
+		}
+		readErr = scanner.Err()
+		if len(b.lines) > 0 {
+			send()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				t.convertBatch(b)
+				results <- b
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	bw := bufio.NewWriter(w)
+	pending := make(map[int]*transcoderBatch)
+	next := 0
+	var err error
+	for b := range results {
+		if err != nil {
+			// drain after abort
+			<-tokens
+			continue
+		}
+		pending[b.seq] = b
+		for b = pending[next]; b != nil; b = pending[next] {
+			delete(pending, next)
+			next++
+			<-tokens
+			if err == nil {
+				err = t.writeBatch(bw, b)
+				if err != nil {
+					close(done)
+				}
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if readErr != nil {
+		// keep the lines converted before the read error
+		bw.Flush()
+		return readErr
+	}
+	return bw.Flush()
+}
+
+// convertBatch converts all the lines in the batch.
+func (t *Transcoder) convertBatch(b *transcoderBatch) {
+	b.out = make([]string, len(b.lines))
+	b.errs = make([]error, len(b.lines))
+	for i, l := range b.lines {
+		if b.first+i <= t.Header {
+			b.out[i] = l
+			continue
+		}
+		b.out[i], b.errs[i] = t.convertLine(l)
+	}
+}
+
+// writeBatch writes the converted lines, handling the errors in input order.
+func (t *Transcoder) writeBatch(w *bufio.Writer, b *transcoderBatch) error {
+	for i, out := range b.out {
+		if b.errs[i] != nil {
+			lerr := &LineError{b.first + i, b.lines[i], b.errs[i]}
+			if t.OnError == nil {
+				return lerr
+			}
+			if err := t.OnError(lerr); err != nil {
+				return err
+			}
+			out = b.lines[i]
+		}
+		w.WriteString(out)
+		if err := w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertLine converts the UUID in a single line.
+func (t *Transcoder) convertLine(line string) (string, error) {
+	eol := ""
+	if strings.HasSuffix(line, "\r") {
+		line, eol = line[:len(line)-1], "\r"
+	}
+	if strings.TrimSpace(line) == "" {
+		return line + eol, nil
+	}
+	if t.Column <= 0 {
+		s, err := t.convertField(strings.TrimSpace(line))
+		return s + eol, err
+	}
+
+	delim := t.Delimiter
+	if delim == 0 {
+		delim = ','
+	}
+	start := 0
+	for col := 1; col < t.Column; col++ {
+		i := strings.IndexByte(line[start:], delim)
+		if i < 0 {
+			return "", fmt.Errorf("missing column %d", t.Column)
+		}
+		start += i + 1
+	}
+	end := len(line)
+	if i := strings.IndexByte(line[start:], delim); i >= 0 {
+		end = start + i
+	}
+	field := strings.TrimSpace(line[start:end])
+	quoted := len(field) >= 2 && field[0] == '"' && field[len(field)-1] == '"'
+	if quoted {
+		field = field[1 : len(field)-1]
+	}
+	s, err := t.convertField(field)
+	if err != nil {
+		return "", err
+	}
+	if quoted {
+		s = `"` + s + `"`
+	}
+	return line[:start] + s + line[end:] + eol, nil
+}
+
+// convertField decodes and re-encodes a single UUID.
+func (t *Transcoder) convertField(field string) (string, error) {
+	b, err := t.Dec.DecodeString(field)
+	if err != nil {
+		return "", err
+	}
+	if len(b) != 16 {
+		return "", fmt.Errorf("uuid.Transcoder: Decoded length is wrong (%d instead of 16)", len(b))
+	}
+	return t.Enc.EncodeToString(b), nil
+}
+
+// scanLinesKeepCR is a bufio.SplitFunc like bufio.ScanLines, except that it keeps any trailing
+// carriage return, so that CRLF line endings can be preserved.
+func scanLinesKeepCR(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestTranscoder(t *testing.T) {
+	var in, exp strings.Builder
+	for i, tc := range encTCs {
+		fmt.Fprintf(&in, "%s\n", tc.src)
+		fmt.Fprintf(&exp, "%s\n", tc.b64u)
+		if i == 3 {
+			in.WriteString("\n")
+			exp.WriteString("\n")
+		}
+	}
+	tr := &Transcoder{Dec: FormatCanonical, Enc: Base64URLEncoder, Workers: 3, BatchSize: 2}
+	var out bytes.Buffer
+	if err := tr.Transcode(strings.NewReader(in.String()), &out); err != nil {
+		t.Fatal("TestTranscoder:", err)
+	}
+	if out.String() != exp.String() {
+		t.Errorf("TestTranscoder: got\n%s\nwant\n%s", out.String(), exp.String())
+	}
+
+	// and back, with CRLF line endings
+	tr = &Transcoder{Dec: Base64URLEncoder, Enc: FormatCanonical}
+	out.Reset()
+	if err := tr.Transcode(strings.NewReader(strings.Replace(exp.String(), "\n", "\r\n", -1)), &out); err != nil {
+		t.Fatal("TestTranscoder:", err)
+	}
+	if act, want := out.String(), strings.Replace(in.String(), "\n", "\r\n", -1); act != want {
+		t.Errorf("TestTranscoder: got\n%q\nwant\n%q", act, want)
+	}
+}
+
+func TestTranscoderColumns(t *testing.T) {
+	in := "id;name;owner\n" +
+		"1;first;f254df4a-184c-1019-80a4-c61cd00a6899\n" +
+		"2;second;\"86ef2c67-ccae-4241-8543-622e8589c62a\"\n" +
+		"3;third;not a uuid\n" +
+		"4;fourth\n"
+	exp := "id;name;owner\n" +
+		"1;first;8lTfShhMEBmApMYc0ApomQ\n" +
+		"2;second;\"hu8sZ8yuQkGFQ2IuhYnGKg\"\n" +
+		"3;third;not a uuid\n" +
+		"4;fourth\n"
+	var lines []int
+	tr := &Transcoder{
+		Dec:       FormatCanonical,
+		Enc:       Base64URLEncoder,
+		Column:    3,
+		Delimiter: ';',
+		BatchSize: 1,
+		OnError: func(e *LineError) error {
+			lines = append(lines, e.Line)
+			return nil
+		},
+	}
+	var out bytes.Buffer
+	if err := tr.Transcode(strings.NewReader(in), &out); err != nil {
+		t.Fatal("TestTranscoderColumns:", err)
+	}
+	if out.String() != exp {
+		t.Errorf("TestTranscoderColumns: got\n%s\nwant\n%s", out.String(), exp)
+	}
+	if fmt.Sprint(lines) != "[1 4 5]" {
+		t.Errorf("TestTranscoderColumns: expecting errors on lines [1 4 5], got %v", lines)
+	}
+
+	// header line copied unchanged
+	lines = nil
+	tr.Header = 1
+	out.Reset()
+	if err := tr.Transcode(strings.NewReader(in), &out); err != nil {
+		t.Fatal("TestTranscoderColumns:", err)
+	}
+	if out.String() != exp {
+		t.Errorf("TestTranscoderColumns: got\n%s\nwant\n%s", out.String(), exp)
+	}
+	if fmt.Sprint(lines) != "[4 5]" {
+		t.Errorf("TestTranscoderColumns: expecting errors on lines [4 5], got %v", lines)
+	}
+
+	// middle column, default delimiter
+	tr = &Transcoder{Dec: FormatCanonical, Enc: FormatHex, Column: 2}
+	out.Reset()
+	if err := tr.Transcode(strings.NewReader("a,"+uuidString+",b\n"), &out); err != nil {
+		t.Fatal("TestTranscoderColumns:", err)
+	}
+	if act, want := out.String(), "a,f254df4a184c101980a4c61cd00a6899,b\n"; act != want {
+		t.Errorf("TestTranscoderColumns: got %s want %s", act, want)
+	}
+}
+
+func TestTranscoderErrors(t *testing.T) {
+	var in strings.Builder
+	for i := 0; i < 10000; i++ {
+		if i == 7777 {
+			in.WriteString("malformed\n")
+		} else {
+			fmt.Fprintf(&in, "%s\n", New())
+		}
+	}
+	tr := &Transcoder{Dec: FormatCanonical, Enc: Z85Encoder, Workers: 4, BatchSize: 100}
+	var out bytes.Buffer
+	err := tr.Transcode(strings.NewReader(in.String()), &out)
+	lerr, ok := err.(*LineError)
+	if !ok {
+		t.Fatalf("TestTranscoderErrors: expecting *LineError, got %v", err)
+	}
+	if lerr.Line != 7778 || lerr.Text != "malformed" {
+		t.Errorf("TestTranscoderErrors: expecting error on line 7778, got %v (%q)", lerr, lerr.Text)
+	}
+
+	abort := errors.New("abort")
+	tr.OnError = func(*LineError) error { return abort }
+	if err = tr.Transcode(strings.NewReader(in.String()), &out); err != abort {
+		t.Errorf("TestTranscoderErrors: expecting OnError result, got %v", err)
+	}
+
+	tr = &Transcoder{Dec: Base64URLEncoder, Enc: FormatCanonical}
+	if err = tr.Transcode(strings.NewReader("AAAA\n"), &out); err == nil {
+		t.Error("TestTranscoderErrors: should fail on wrong decoded length")
+	}
+}
+
+func TestTranscoderReadError(t *testing.T) {
+	var in, exp strings.Builder
+	for i := 0; i < 250; i++ {
+		u := New()
+		fmt.Fprintf(&in, "%s\n", u)
+		fmt.Fprintf(&exp, "%s\n", u.EncodeToString(Base64URLEncoder))
+	}
+	readErr := errors.New("read error")
+	tr := &Transcoder{Dec: FormatCanonical, Enc: Base64URLEncoder, Workers: 4, BatchSize: 100}
+	var out bytes.Buffer
+	if err := tr.Transcode(io.MultiReader(strings.NewReader(in.String()), iotest.ErrReader(readErr)), &out); err != readErr {
+		t.Errorf("TestTranscoderReadError: expecting read error, got %v", err)
+	}
+	if out.String() != exp.String() {
+		t.Errorf("TestTranscoderReadError: expecting the %d lines read before the error, got %d", 250, strings.Count(out.String(), "\n"))
+	}
+}
+
+func TestTranscoderOrder(t *testing.T) {
+	var in, exp strings.Builder
+	for i := 0; i < 50000; i++ {
+		u := NewCrypto()
+		fmt.Fprintf(&in, "%d,%s\n", i, u)
+		fmt.Fprintf(&exp, "%d,%s\n", i, u.EncodeToString(Base64URLEncoder))
+	}
+	tr := &Transcoder{Dec: FormatCanonical, Enc: Base64URLEncoder, Column: 2, Workers: 8, BatchSize: 64}
+	var out bytes.Buffer
+	if err := tr.Transcode(strings.NewReader(in.String()), &out); err != nil {
+		t.Fatal("TestTranscoderOrder:", err)
+	}
+	if out.String() != exp.String() {
+		t.Error("TestTranscoderOrder: output does not match input order")
+	}
+}
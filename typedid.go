@@ -0,0 +1,181 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	typedIDMaxPrefix = 63
+	typedIDSuffixLen = 26
+)
+
+// TypedID pairs a type prefix with a UUID, compatible with the TypeID specification
+// (see https://github.com/jetify-com/typeid/tree/main/spec), e.g. "user_01h455vb4pex5vsknk084sn02q".
+//
+// The prefix is at most 63 lower-case ASCII letters and underscores, starting and ending with a letter,
+// and may be empty. The suffix is the UUID encoded with CrockfordBase32Encoder.
+//
+// When unmarshaling into a TypedID whose Prefix is already set, a different prefix is rejected,
+// so that a field can be declared to hold IDs of a single type:
+//
+//	id := uuid.TypedID{Prefix: "user"}
+//	err := json.Unmarshal(data, &id)
+type TypedID struct {
+	Prefix string
+	UUID   UUID
+}
+
+// NewTypedID creates a new TypedID with the provided prefix and a new UUID v7.
+func NewTypedID(prefix string) (TypedID, error) {
+	if err := validateTypedIDPrefix(prefix); err != nil {
+		return TypedID{}, fmt.Errorf("uuid.NewTypedID: %v", err)
+	}
+	return TypedID{prefix, NewV7()}, nil
+}
+
+// NewTypedIDFromUUID creates a TypedID with the provided prefix and UUID.
+func NewTypedIDFromUUID(prefix string, u UUID) (TypedID, error) {
+	if err := validateTypedIDPrefix(prefix); err != nil {
+		return TypedID{}, fmt.Errorf("uuid.NewTypedIDFromUUID: %v", err)
+	}
+	if len(u) != 16 {
+		return TypedID{}, fmt.Errorf("uuid.NewTypedIDFromUUID: UUID length is wrong (%d instead of 16)", len(u))
+	}
+	return TypedID{prefix, u}, nil
+}
+
+// ParseTypedID parses a TypedID from its string form, accepting any valid prefix.
+func ParseTypedID(s string) (TypedID, error) {
+	t, err := parseTypedID(s)
+	if err != nil {
+		return TypedID{}, fmt.Errorf("uuid.ParseTypedID: %v", err)
+	}
+	return t, nil
+}
+
+// ParseTypedIDWithPrefix parses a TypedID from its string form, rejecting any prefix other than the provided one.
+func ParseTypedIDWithPrefix(prefix, s string) (TypedID, error) {
+	t, err := parseTypedID(s)
+	if err != nil {
+		return TypedID{}, fmt.Errorf("uuid.ParseTypedIDWithPrefix: %v", err)
+	}
+	if t.Prefix != prefix {
+		return TypedID{}, fmt.Errorf("uuid.ParseTypedIDWithPrefix: prefix %q does not match expected %q", t.Prefix, prefix)
+	}
+	return t, nil
+}
+
+// String formats the receiver TypedID as prefix, underscore and suffix, or just the suffix if the prefix is empty.
+func (t TypedID) String() string {
+	suffix := CrockfordBase32Encoder.EncodeToString(t.UUID)
+	if t.Prefix == "" {
+		return suffix
+	}
+	return t.Prefix + "_" + suffix
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (t TypedID) MarshalText() ([]byte, error) {
+	if len(t.UUID) != 16 {
+		return nil, fmt.Errorf("uuid.TypedID.MarshalText: UUID length is wrong (%d instead of 16)", len(t.UUID))
+	}
+	if err := validateTypedIDPrefix(t.Prefix); err != nil {
+		return nil, fmt.Errorf("uuid.TypedID.MarshalText: %v", err)
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// If the receiver Prefix is set, the parsed prefix must match it.
+func (t *TypedID) UnmarshalText(b []byte) error {
+	parsed, err := parseTypedID(string(b))
+	if err != nil {
+		return fmt.Errorf("uuid.TypedID.UnmarshalText: %v", err)
+	}
+	if t.Prefix != "" && t.Prefix != parsed.Prefix {
+		return fmt.Errorf("uuid.TypedID.UnmarshalText: prefix %q does not match expected %q", parsed.Prefix, t.Prefix)
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t TypedID) MarshalJSON() ([]byte, error) {
+	b, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(`"` + string(b) + `"`), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// If the receiver Prefix is set, the parsed prefix must match it.
+func (t *TypedID) UnmarshalJSON(b []byte) error {
+	var field string
+	if err := json.Unmarshal(b, &field); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(field))
+}
+
+// parseTypedID parses a TypedID, enforcing the TypeID specification.
+func parseTypedID(s string) (TypedID, error) {
+	var prefix, suffix string
+	if i := strings.LastIndexByte(s, '_'); i >= 0 {
+		prefix, suffix = s[:i], s[i+1:]
+		if prefix == "" {
+			return TypedID{}, fmt.Errorf("%s has an empty prefix with separator", s)
+		}
+	} else {
+		suffix = s
+	}
+	if err := validateTypedIDPrefix(prefix); err != nil {
+		return TypedID{}, err
+	}
+	if len(suffix) != typedIDSuffixLen {
+		return TypedID{}, fmt.Errorf("suffix %s has wrong length (%d instead of %d)", suffix, len(suffix), typedIDSuffixLen)
+	}
+	if suffix[0] > '7' {
+		return TypedID{}, fmt.Errorf("suffix %s overflows 128 bits", suffix)
+	}
+	for i := 0; i < len(suffix); i++ {
+		if strings.IndexByte(crockfordAlphabet, suffix[i]) < 0 {
+			return TypedID{}, fmt.Errorf("suffix %s has invalid character %q", suffix, suffix[i])
+		}
+	}
+	u, err := CrockfordBase32Encoder.DecodeString(suffix)
+	if err != nil {
+		return TypedID{}, err
+	}
+	return TypedID{prefix, UUID(u)}, nil
+}
+
+// validateTypedIDPrefix checks the prefix against the TypeID specification.
+func validateTypedIDPrefix(prefix string) error {
+	if len(prefix) > typedIDMaxPrefix {
+		return fmt.Errorf("prefix %s is too long (%d, max %d)", prefix, len(prefix), typedIDMaxPrefix)
+	}
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		if (c < 'a' || c > 'z') && (c != '_' || i == 0 || i == len(prefix)-1) {
+			return fmt.Errorf("prefix %s has invalid character %q at offset %d", prefix, c, i)
+		}
+	}
+	return nil
+}
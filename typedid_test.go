@@ -0,0 +1,148 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTypedID(t *testing.T) {
+	// valid test vectors from the TypeID specification
+	tcs := []struct {
+		typeid string
+		prefix string
+		uuid   string
+	}{
+		{"00000000000000000000000000", "", "00000000-0000-0000-0000-000000000000"},
+		{"00000000000000000000000001", "", "00000000-0000-0000-0000-000000000001"},
+		{"0000000000000000000000000a", "", "00000000-0000-0000-0000-00000000000a"},
+		{"0000000000000000000000000g", "", "00000000-0000-0000-0000-000000000010"},
+		{"00000000000000000000000010", "", "00000000-0000-0000-0000-000000000020"},
+		{"7zzzzzzzzzzzzzzzzzzzzzzzzz", "", "ffffffff-ffff-ffff-ffff-ffffffffffff"},
+		{"prefix_0123456789abcdefghjkmnpqrs", "prefix", "0110c853-1d09-52d8-d73e-1194e95b5f19"},
+		{"prefix_01h455vb4pex5vsknk084sn02q", "prefix", "01890a5d-ac96-774b-bcce-b302099a8057"},
+		{"pre_fix_00000000000000000000000000", "pre_fix", "00000000-0000-0000-0000-000000000000"},
+	}
+	for _, tc := range tcs {
+		id, err := ParseTypedID(tc.typeid)
+		if err != nil {
+			t.Errorf("TestTypedID(%s): %s", tc.typeid, err)
+			continue
+		}
+		if id.Prefix != tc.prefix || id.UUID.String() != tc.uuid {
+			t.Errorf("TestTypedID(%s): got %s %s want %s %s", tc.typeid, id.Prefix, id.UUID, tc.prefix, tc.uuid)
+		}
+		u, _ := NewFromString(tc.uuid)
+		id, err = NewTypedIDFromUUID(tc.prefix, u)
+		if err != nil {
+			t.Errorf("TestTypedID(%s): %s", tc.typeid, err)
+		} else if act := id.String(); act != tc.typeid {
+			t.Errorf("TestTypedID(%s): got %s", tc.typeid, act)
+		}
+	}
+
+	// invalid test vectors from the TypeID specification
+	for _, s := range []string{
+		"PREFIX_00000000000000000000000000",
+		"12345_00000000000000000000000000",
+		"pre.fix_00000000000000000000000000",
+		"préfix_00000000000000000000000000",
+		"  prefix_00000000000000000000000000",
+		"abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijkl_00000000000000000000000000",
+		"_00000000000000000000000000",
+		"_",
+		"prefix_1234567890123456789012345",
+		"prefix_123456789012345678901234567",
+		"prefix_1234567890123456789012345 ",
+		"prefix_0123456789ABCDEFGHJKMNPQRS",
+		"prefix_123456789-123456789-123456",
+		"prefix_ooooooiiiiiiuuuuuuulllllll",
+		"prefix_i23456789ol23456789oi23456",
+		"prefix_",
+		"_prefix_00000000000000000000000000",
+		"prefix__00000000000000000000000000",
+		"prefix_8zzzzzzzzzzzzzzzzzzzzzzzzz",
+	} {
+		if _, err := ParseTypedID(s); err == nil {
+			t.Errorf("TestTypedID(%s): expecting error, got nil", s)
+		}
+	}
+
+	if _, err := NewTypedIDFromUUID("user", UUID(zero[1:])); err == nil {
+		t.Error("TestTypedID: NewTypedIDFromUUID should fail on short UUID")
+	}
+	if _, err := NewTypedID("User"); err == nil {
+		t.Error("TestTypedID: NewTypedID should fail on invalid prefix")
+	}
+}
+
+func TestNewTypedID(t *testing.T) {
+	id, err := NewTypedID("user")
+	if err != nil {
+		t.Fatal("TestNewTypedID:", err)
+	}
+	if id.UUID.Version() != 7 {
+		t.Errorf("TestNewTypedID: expecting version 7, got %d", id.UUID.Version())
+	}
+	s := id.String()
+	if !strings.HasPrefix(s, "user_") || len(s) != len("user_")+26 {
+		t.Errorf("TestNewTypedID: unexpected format %s", s)
+	}
+
+	id2, err := ParseTypedIDWithPrefix("user", s)
+	if err != nil {
+		t.Error("TestNewTypedID:", err)
+	} else if id2.String() != s {
+		t.Errorf("TestNewTypedID: got %s want %s", id2, s)
+	}
+	if _, err = ParseTypedIDWithPrefix("account", s); err == nil {
+		t.Error("TestNewTypedID: ParseTypedIDWithPrefix should fail on mismatched prefix")
+	}
+}
+
+func TestTypedIDJSON(t *testing.T) {
+	id, _ := ParseTypedID("prefix_01h455vb4pex5vsknk084sn02q")
+	b, err := json.Marshal(struct{ ID TypedID }{id})
+	if err != nil {
+		t.Fatal("TestTypedIDJSON:", err)
+	}
+	if act, want := string(b), `{"ID":"prefix_01h455vb4pex5vsknk084sn02q"}`; act != want {
+		t.Errorf("TestTypedIDJSON: got %s want %s", act, want)
+	}
+
+	d := struct{ ID TypedID }{}
+	if err = json.Unmarshal(b, &d); err != nil {
+		t.Error("TestTypedIDJSON:", err)
+	} else if d.ID.String() != id.String() {
+		t.Errorf("TestTypedIDJSON: got %s want %s", d.ID, id)
+	}
+
+	d.ID = TypedID{Prefix: "prefix"}
+	if err = json.Unmarshal(b, &d); err != nil {
+		t.Error("TestTypedIDJSON:", err)
+	}
+	d.ID = TypedID{Prefix: "user"}
+	if err = json.Unmarshal(b, &d); err == nil {
+		t.Error("TestTypedIDJSON: should fail on mismatched prefix")
+	}
+	if err = json.Unmarshal([]byte(`{"ID":"prefix_8zzzzzzzzzzzzzzzzzzzzzzzzz"}`), &d); err == nil {
+		t.Error("TestTypedIDJSON: should fail on invalid TypeID")
+	}
+	if _, err = json.Marshal(TypedID{Prefix: "user"}); err == nil {
+		t.Error("TestTypedIDJSON: should fail to marshal without UUID")
+	}
+}
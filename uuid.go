@@ -19,21 +19,27 @@ Version 1 UUIDs are time-based and include a node identifier that can be a MAC a
 
 This package uses the random approach for the node identifier, setting both the 'multicast' and 'local' bits to make sure the value cannot be confused with a real IEEE 802 address (see section 4.5 of RFC 4122). The initial node identifier is a cryptographic-quality random 46-bit value. The first 30 bits can be set and retrieved with the `SetNodeId` and `NodeId` functions and method, so that they can be used as a hard-coded instance id. The remaining 16 bits are reserved for increasing the randomness of the UUIDs and to avoid collisions on clock sequence rollovers.
 
-The basic generator `New` increments the clock sequence on every call and when the counter rolls over the last 16 bits of the node identifier are regenerated using a PRNG seeded at init()-time with the initial node identifier. This approach sacrifices cryptographic quality for speed and for avoiding depletion of the OS entropy pool (yes, it can and does happen).
+The basic generator `New` increments the clock sequence on every call and when the counter rolls over the last 16 bits of the node identifier are regenerated using a private PRNG (ChaCha8 by default, see `GeneratorConfig` and `SetDefaultGenerator`) seeded at init()-time with cryptographic-quality random values, leaving the global `math/rand` state untouched. This approach sacrifices cryptographic quality for speed and for avoiding depletion of the OS entropy pool (yes, it can and does happen).
 
 The `NewCrypto` generator replaces the clock sequence and last 16 bits of the node identifier on each call with cryptographic-quality random values.
+
+The `NewBatch`, `Fill` and `FillArray` functions generate many UUIDs v1 at once, such as for bulk inserts, reserving the clock sequence values under a single lock. The UUIDs in a batch are strictly ordered by timestamp, then clock sequence.
+
+The `NewV7` generator creates version 7 UUIDs (defined in RFC 9562), which start with a Unix timestamp of millisecond precision followed by sub-millisecond precision and cryptographic-quality random bits, so they sort in creation order.
+
+The `NewGenerator` function creates independent generators, with their own clock sequence and node identifier. Their node id can be sourced from the host name (`NodeFromHostname`), an environment variable (`NodeFromEnv`), the ordinal of a Kubernetes StatefulSet pod (`NodeFromStatefulSet`), or leased from a range of node ids shared by the processes on a host (`NodeFromLeaser` with a `FileNodeLeaser`). With `NodeFromMAC`, the generator uses a hardware MAC address as node identifier instead, in the classic mode of RFC 4122. `SetDefaultGenerator` replaces the generator used by the package-level functions, e.g. to select its entropy policy, PRNG, health tests or observer.
+
+UUIDs implement `slog.LogValuer`, rendering as their canonical string, or as a group with their version and time after `SetLogDetail(true)`. The `LogHandler` adds the request UUID stored in the context by `NewContext` to every record logged with that context.
 */
 package uuid
 
 import (
-	"crypto/rand"
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	mrand "math/rand"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -84,37 +90,52 @@ const (
 type UUID []byte
 
 var (
-	csanMutex       sync.RWMutex
-	randBuf         []byte
-	randBufCap      = 256
-	randBufOffset   int
-	clockSeqAndNode uint64
-	clockSeq        uint16
-	nodeRand        uint16
+	// defaultGenerator is the Generator used by the package-level functions, see SetDefaultGenerator.
+	defaultGenerator atomic.Pointer[Generator]
+	randBufCap       = 256
 	// aliases to allow mocking in tests
 	timeNow = time.Now
 )
 
+var (
+	// NamespaceDNS is the name space ID for fully-qualified domain names (see RFC 4122 appendix C)
+	NamespaceDNS = UUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	// NamespaceURL is the name space ID for URLs (see RFC 4122 appendix C)
+	NamespaceURL = UUID{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	// NamespaceOID is the name space ID for ISO OIDs (see RFC 4122 appendix C)
+	NamespaceOID = UUID{0x6b, 0xa7, 0xb8, 0x12, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	// NamespaceX500 is the name space ID for X.500 DNs in DER or text output format (see RFC 4122 appendix C)
+	NamespaceX500 = UUID{0x6b, 0xa7, 0xb8, 0x14, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+)
+
 func init() {
-	randBuf = make([]byte, 8, randBufCap)
-	n, _ := rand.Read(randBuf)
-	for i := 0; i < 8 && n < 8; i++ {
-		n2, _ := rand.Read(randBuf[n:])
-		n += n2
+	g := &Generator{}
+	if err := g.init(nil); err != nil {
+		panic(fmt.Sprintf("uuid.init: %v", err))
 	}
-	if n < 8 {
-		panic(fmt.Sprintf("uuid.init: Could not generate %d random bytes (got %d)", 8, n))
+	defaultGenerator.Store(g)
+}
+
+// SetDefaultGenerator replaces the Generator used by the package-level functions, e.g. to select
+// its entropy policy, PRNG, health tests or observer:
+//
+//	g, err := uuid.NewGenerator(uuid.GeneratorConfig{EntropyPolicy: uuid.EntropyError})
+//	...
+//	uuid.SetDefaultGenerator(g)
+//
+// The node id set with SetNodeId applies to the replaced generator only; set it with GeneratorConfig.Node instead.
+// An error is returned if g is nil, and the default generator is then left unchanged.
+func SetDefaultGenerator(g *Generator) error {
+	if g == nil {
+		return fmt.Errorf("uuid.SetDefaultGenerator: generator is nil")
 	}
-	// set the variant inside the clock sequence
-	randBuf[0] = uint8(randBuf[0]&0x1f | /*variant*/ 1<<5)
-	// set the 'local' and 'multicast' bits of the MAC replacement,
-	// to avoid conflicts with real MAC addresses.
-	randBuf[2] = uint8((randBuf[2] << 2) | 0x03)
+	defaultGenerator.Store(g)
+	return nil
+}
 
-	clockSeqAndNode = binary.BigEndian.Uint64(randBuf)
-	clockSeq = uint16((clockSeqAndNode >> 48) & 0x1fff)
-	nodeRand = uint16(clockSeqAndNode & 0xffff)
-	mrand.Seed(int64(clockSeqAndNode))
+// DefaultGenerator returns the Generator used by the package-level functions.
+func DefaultGenerator() *Generator {
+	return defaultGenerator.Load()
 }
 
 // SetNodeId sets the bits corresponding to the node id.
@@ -122,75 +143,54 @@ func init() {
 // but only the least significant 30 bits are used. An error is returned
 // if the discarded, most significant 2 bits are non-zero.
 func SetNodeId(nodeId uint32) error {
-	csanMutex.Lock()
-	// keep the clock sequence, node counter, and the 'local' and 'multicast' bits
-	// of the MAC replacement, to avoid conflicts with real MAC addresses.
-	clockSeqAndNode = (clockSeqAndNode & 0xffff03000000ffff) |
-		(uint64(((nodeId&0x3f000000)<<2)|(nodeId&0x00ffffff)) << 16)
-	csanMutex.Unlock()
-	if nodeId>>30 != 0 {
-		return fmt.Errorf("uuid.SetNodeId: discarded non-zero most significant 2 bits from nodeId %x", nodeId)
+	if err := defaultGenerator.Load().setNodeId(nodeId); err != nil {
+		return fmt.Errorf("uuid.SetNodeId: %v", err)
 	}
 	return nil
 }
 
 // NodeId returns the current node id used to generate UUIDs.
 func NodeId() uint32 {
-	csanMutex.Lock()
-	nodeId := clockSeqAndNode >> 16
-	csanMutex.Unlock()
-	return uint32((nodeId & 0x00ffffff) | ((nodeId & 0xfc000000) >> 2))
+	return defaultGenerator.Load().NodeId()
 }
 
 // New creates a new UUID v1 from the current time, clock sequence and node identifier.
 func New() UUID {
-	uuid := make([]byte, 16)
-
-	csanMutex.Lock()
-	if clockSeq = (clockSeq + 1) & 0x1fff; clockSeq == 0 {
-		nodeRand = uint16(mrand.Int31n(0x10000))
-	}
-	clockSeqAndNode = (clockSeqAndNode & 0xe000ffffffff0000) |
-		((uint64(clockSeq)) << 48) | uint64(nodeRand)
-	binary.BigEndian.PutUint64(uuid[8:], uint64(clockSeqAndNode))
-	csanMutex.Unlock()
-
-	ts := fromUnixNano(int64(timeNow().UTC().UnixNano()))
-	// "timestamp" multiplexed with version
-	binary.BigEndian.PutUint32(uuid[0:4], uint32(ts&0xffffffff))
-	binary.BigEndian.PutUint16(uuid[4:6], uint16((ts>>32)&0xffff))
-	binary.BigEndian.PutUint16(uuid[6:8], uint16((ts>>48)&0x0fff)| /*version*/ 1<<12)
-
-	return UUID(uuid)
+	return defaultGenerator.Load().New()
 }
 
 // NewCrypto creates a new UUID v1 from the current time, with cryptographic-quality random clock sequence and last 16 bits of the node identifier.
 func NewCrypto() UUID {
-	uuid := make([]byte, 16)
+	return defaultGenerator.Load().NewCrypto()
+}
 
-	csanMutex.Lock()
-	n, _ := rand.Read(randBuf[randBufOffset : randBufOffset+4])
-	if randBufOffset += n - 4; randBufOffset < 0 {
-		randBufOffset = 0
-	}
-	val := binary.BigEndian.Uint32(randBuf[randBufOffset : randBufOffset+4])
-	if randBufOffset += 4; randBufOffset > randBufCap-4 {
-		randBufOffset = 0
-	}
-	clockSeq = uint16((val >> 16) & 0x1fff)
-	nodeRand = uint16(val & 0xffff)
-	clockSeqAndNode = (clockSeqAndNode & 0xe000ffffffff0000) |
-		((uint64(clockSeq)) << 48) | uint64(nodeRand)
-	binary.BigEndian.PutUint64(uuid[8:], uint64(clockSeqAndNode))
-	csanMutex.Unlock()
+// NewCryptoE creates a new UUID v1 as NewCrypto does, returning an error if the random source fails
+// and the entropy policy of the default generator is EntropyError (see SetDefaultGenerator).
+// With the initial default generator, which falls back to its PRNG, it never returns an error.
+func NewCryptoE() (UUID, error) {
+	return defaultGenerator.Load().NewCryptoE()
+}
 
-	ts := fromUnixNano(int64(timeNow().UTC().UnixNano()))
-	// "timestamp" multiplexed with version
-	binary.BigEndian.PutUint32(uuid[0:4], uint32(ts&0xffffffff))
-	binary.BigEndian.PutUint16(uuid[4:6], uint16((ts>>32)&0xffff))
-	binary.BigEndian.PutUint16(uuid[6:8], uint16((ts>>48)&0x0fff)| /*version*/ 1<<12)
+// NewBatch creates n new UUIDs v1, as Fill does.
+func NewBatch(n int) []UUID {
+	return defaultGenerator.Load().NewBatch(n)
+}
+
+// Fill fills dst with len(dst)/16 new UUIDs v1, as New does, leaving any remaining bytes unchanged.
+// The UUIDs are strictly ordered by Timestamp, then ClockSequence.
+func Fill(dst []byte) {
+	defaultGenerator.Load().Fill(dst)
+}
 
-	return UUID(uuid)
+// FillArray fills dst with new UUIDs v1, as Fill does.
+func FillArray(dst [][16]byte) {
+	defaultGenerator.Load().FillArray(dst)
+}
+
+// NewV7 creates a new UUID v7 from the current time, with 12 bits of sub-millisecond precision
+// and 62 bits of cryptographic-quality randomness.
+func NewV7() UUID {
+	return defaultGenerator.Load().NewV7()
 }
 
 // NewFromBytes creates a UUID from a slice of byte; mostly useful for copying UUIDs.
@@ -218,6 +218,32 @@ func NewFromString(s string) (UUID, error) {
 	return UUID(uuid), nil
 }
 
+// NewFromEncoded creates a UUID by decoding the source with the provided Decoder.
+func NewFromEncoded(d Decoder, src []byte) (UUID, error) {
+	b, err := d.Decode(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 16 {
+		return nil, fmt.Errorf("uuid.NewFromEncoded: Decoded length is wrong (%d instead of 16)", len(b))
+	}
+
+	return UUID(b), nil
+}
+
+// NewFromEncodedString creates a UUID by decoding the source string with the provided DecoderFromString.
+func NewFromEncodedString(d DecoderFromString, s string) (UUID, error) {
+	b, err := d.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 16 {
+		return nil, fmt.Errorf("uuid.NewFromEncodedString: Decoded length is wrong (%d instead of 16)", len(b))
+	}
+
+	return UUID(b), nil
+}
+
 // Hex formats the receiver UUID as a hex string.
 func (u UUID) Hex() string {
 	return hex.EncodeToString([]byte(u))
@@ -247,12 +273,64 @@ func (u UUID) NodeId() uint32 {
 
 // Time extracts the time from the receiver UUID as time.Time.
 func (u UUID) Time() time.Time {
+	nanosecs := toUnixNano(int64(u.Timestamp()))
+
+	return time.Unix(nanosecs/1e9, nanosecs%1e9).UTC()
+}
+
+// Timestamp extracts the raw 60-bit timestamp from the receiver UUID, as a count of
+// 100-nanosecond intervals since 00:00:00.00, 15 October 1582 (see RFC 4122 section 4.1.4).
+func (u UUID) Timestamp() uint64 {
 	timeLow := uint64(binary.BigEndian.Uint32(u[0:4]))
 	timeMid := uint64(binary.BigEndian.Uint16(u[4:6]))
 	timeHi := uint64((binary.BigEndian.Uint16(u[6:8]) & 0x0fff))
-	nanosecs := toUnixNano(int64((timeLow) + (timeMid << 32) + (timeHi << 48)))
 
-	return time.Unix(nanosecs/1e9, nanosecs%1e9).UTC()
+	return timeLow + (timeMid << 32) + (timeHi << 48)
+}
+
+// ClockSequence extracts the clock sequence from the receiver UUID: 14 bits for the RFC 4122 variant,
+// 13 bits for other variants, such as the one used by New and NewCrypto.
+func (u UUID) ClockSequence() uint16 {
+	if u.Variant()>>1 == 2 {
+		return binary.BigEndian.Uint16(u[8:10]) & 0x3fff
+	}
+	return binary.BigEndian.Uint16(u[8:10]) & 0x1fff
+}
+
+// Node extracts the full 48-bit node identifier from the receiver UUID.
+func (u UUID) Node() uint64 {
+	return binary.BigEndian.Uint64(u[8:16]) & 0xffffffffffff
+}
+
+// FromFields creates a UUID v1 from its fields, as extracted by the Timestamp, ClockSequence, Node
+// and Variant methods. Use variant 4 for RFC 4122 UUIDs from other systems, which have a 14-bit clock sequence.
+// Other variants, such as the one used by New and NewCrypto, have a 13-bit clock sequence.
+func FromFields(timestamp uint64, clockSeq uint16, node uint64, variant int) (UUID, error) {
+	if timestamp>>60 != 0 {
+		return nil, fmt.Errorf("uuid.FromFields: timestamp %x does not fit in 60 bits", timestamp)
+	}
+	if node>>48 != 0 {
+		return nil, fmt.Errorf("uuid.FromFields: node %x does not fit in 48 bits", node)
+	}
+	if variant < 0 || variant > 7 {
+		return nil, fmt.Errorf("uuid.FromFields: variant %d is out of range", variant)
+	}
+	clockSeqAndVariant := uint16(variant) << 13
+	if variant>>1 == 2 {
+		if clockSeq>>14 != 0 {
+			return nil, fmt.Errorf("uuid.FromFields: clock sequence %x does not fit in 14 bits", clockSeq)
+		}
+		clockSeqAndVariant = 0x8000
+	} else if clockSeq>>13 != 0 {
+		return nil, fmt.Errorf("uuid.FromFields: clock sequence %x does not fit in 13 bits", clockSeq)
+	}
+	uuid := make([]byte, 16)
+	binary.BigEndian.PutUint32(uuid[0:4], uint32(timestamp&0xffffffff))
+	binary.BigEndian.PutUint16(uuid[4:6], uint16((timestamp>>32)&0xffff))
+	binary.BigEndian.PutUint16(uuid[6:8], uint16((timestamp>>48)&0x0fff)| /*version*/ 1<<12)
+	binary.BigEndian.PutUint64(uuid[8:16], uint64(clockSeqAndVariant|clockSeq)<<48|node)
+
+	return UUID(uuid), nil
 }
 
 // Version extracts the version of the receiver UUID.
@@ -231,3 +231,90 @@ func TestMarshalJSON(t *testing.T) {
 		t.Errorf("TestMarshalJSON: Expecting %s, got %s", want, got)
 	}
 }
+
+func TestNewV7(t *testing.T) {
+	now := time.Date(2023, 6, 29, 12, 0, 0, 123456789, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() { timeNow = time.Now }()
+
+	uuid1 := NewV7()
+	if uuid1.Version() != 7 {
+		t.Errorf("TestNewV7: Expecting version %d, got %d", 7, uuid1.Version())
+	}
+	if uuid1.Variant()>>1 != 2 {
+		t.Errorf("TestNewV7: Expecting variant %d or %d, got %d", 4, 5, uuid1.Variant())
+	}
+	if act, want := uuid1.Hex()[:16], fmt.Sprintf("%012x7%03x", now.UnixNano()/1e6, 456789*0x1000/1000000); act != want {
+		t.Errorf("TestNewV7: Expecting timestamp %s, got %s", want, act)
+	}
+
+	now = now.Add(time.Microsecond)
+	uuid2 := NewV7()
+	if bytes.Compare(uuid1, uuid2) >= 0 {
+		t.Errorf("TestNewV7: Expecting %s to sort before %s", uuid1, uuid2)
+	}
+}
+
+func TestFields(t *testing.T) {
+	uuid1, _ := NewFromBytes(uuid)
+	if act, want := uuid1.Timestamp(), uint64(0x019184cf254df4a); act != want {
+		t.Errorf("TestFields: Expecting timestamp %x, got %x", want, act)
+	}
+	if act, want := uuid1.ClockSequence(), uint16(0x00a4); act != want {
+		t.Errorf("TestFields: Expecting clock sequence %x, got %x", want, act)
+	}
+	if act, want := uuid1.Node(), uint64(0xc61cd00a6899); act != want {
+		t.Errorf("TestFields: Expecting node %x, got %x", want, act)
+	}
+	uuid2, err := FromFields(uuid1.Timestamp(), uuid1.ClockSequence(), uuid1.Node(), uuid1.Variant())
+	if err != nil {
+		t.Error("TestFields:", err)
+	} else if !bytes.Equal(uuid1, uuid2) {
+		t.Errorf("TestFields: Expecting %s, got %s", uuid1, uuid2)
+	}
+
+	// RFC 4122 variant with the most significant bit of the 14-bit clock sequence set
+	uuid1, _ = NewFromString("f254df4a-184c-1019-a0a4-c61cd00a6899")
+	if act, want := uuid1.ClockSequence(), uint16(0x20a4); act != want {
+		t.Errorf("TestFields: Expecting clock sequence %x, got %x", want, act)
+	}
+	uuid2, err = FromFields(uuid1.Timestamp(), uuid1.ClockSequence(), uuid1.Node(), 4)
+	if err != nil {
+		t.Error("TestFields:", err)
+	} else if !bytes.Equal(uuid1, uuid2) {
+		t.Errorf("TestFields: Expecting %s, got %s", uuid1, uuid2)
+	}
+
+	for i := 0; i < 100; i++ {
+		uuid1 = NewCrypto()
+		uuid2, err = FromFields(uuid1.Timestamp(), uuid1.ClockSequence(), uuid1.Node(), uuid1.Variant())
+		if err != nil {
+			t.Fatal("TestFields:", err)
+		}
+		if !bytes.Equal(uuid1, uuid2) {
+			t.Fatalf("TestFields: Expecting %s, got %s", uuid1, uuid2)
+		}
+		if uuid2.NodeId() != NodeId() {
+			t.Fatalf("TestFields: Expecting node id %x, got %x", NodeId(), uuid2.NodeId())
+		}
+	}
+
+	for _, tc := range []struct {
+		timestamp uint64
+		clockSeq  uint16
+		node      uint64
+		variant   int
+	}{
+		{1 << 60, 0, 0, 4},
+		{0, 1 << 14, 0, 4},
+		{0, 1 << 13, 0, 1},
+		{0, 0, 1 << 48, 4},
+		{0, 0, 0, 8},
+	} {
+		if _, err = FromFields(tc.timestamp, tc.clockSeq, tc.node, tc.variant); err == nil {
+			t.Errorf("TestFields(%x, %x, %x, %d): expecting error, got nil", tc.timestamp, tc.clockSeq, tc.node, tc.variant)
+		}
+	}
+}
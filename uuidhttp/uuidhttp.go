@@ -0,0 +1,111 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package uuidhttp propagates request IDs, as UUIDs, through HTTP servers and clients.
+
+The RequestID middleware reads the request ID from the incoming X-Request-ID header, or generates one
+if it is absent or not a valid UUID, stores it in the request context and echoes it on the response:
+
+	http.ListenAndServe(addr, uuidhttp.Middleware(mux))
+
+Handlers retrieve it with FromContext, and the Transport propagates it to outgoing requests:
+
+	client := &http.Client{Transport: &uuidhttp.Transport{}}
+	req, _ := http.NewRequestWithContext(r.Context(), "GET", url, nil)
+	resp, err := client.Do(req)
+*/
+package uuidhttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/agext/uuid"
+)
+
+// DefaultHeader is the default header carrying the request ID.
+const DefaultHeader = "X-Request-ID"
+
+// NewContext returns a copy of the parent context carrying the request ID, as uuid.NewContext does.
+func NewContext(parent context.Context, id uuid.UUID) context.Context {
+	return uuid.NewContext(parent, id)
+}
+
+// FromContext returns the request ID carried by the context, if any, as uuid.FromContext does.
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	return uuid.FromContext(ctx)
+}
+
+// RequestID is an HTTP middleware handling request IDs.
+type RequestID struct {
+	// Header is the request and response header carrying the request ID; the default is DefaultHeader.
+	Header string
+	// Generate creates the request ID when the incoming one is absent or invalid; the default is uuid.New.
+	// It can be set to the New method of a configured uuid.Generator, or to uuid.NewV7.
+	Generate func() uuid.UUID
+}
+
+// Middleware wraps the handler with a RequestID middleware with the default configuration.
+func Middleware(next http.Handler) http.Handler {
+	return (&RequestID{}).Handler(next)
+}
+
+// Handler wraps the handler, so that it serves the requests with their request ID in the context,
+// after setting the response header.
+func (m *RequestID) Handler(next http.Handler) http.Handler {
+	header := m.Header
+	if header == "" {
+		header = DefaultHeader
+	}
+	generate := m.Generate
+	if generate == nil {
+		generate = uuid.New
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.NewFromString(r.Header.Get(header))
+		if err != nil {
+			id = generate()
+		}
+		w.Header().Set(header, id.String())
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// Transport is an http.RoundTripper that sets the request ID carried by the context of outgoing requests
+// in their header, unless already set.
+type Transport struct {
+	// Header is the request header carrying the request ID; the default is DefaultHeader.
+	Header string
+	// Base is the underlying RoundTripper; the default is http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	header := t.Header
+	if header == "" {
+		header = DefaultHeader
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if id, ok := FromContext(r.Context()); ok && r.Header.Get(header) == "" {
+		// a RoundTripper must not modify the request
+		r = r.Clone(r.Context())
+		r.Header.Set(header, id.String())
+	}
+	return base.RoundTrip(r)
+}
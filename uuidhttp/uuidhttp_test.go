@@ -0,0 +1,120 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuidhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agext/uuid"
+)
+
+func TestMiddleware(t *testing.T) {
+	var seen uuid.UUID
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := FromContext(r.Context())
+		if !ok {
+			t.Error("TestMiddleware: no request ID in context")
+		}
+		seen = id
+	}))
+
+	for _, tc := range []struct {
+		header string
+		keep   bool
+	}{
+		{"f254df4a-184c-1019-80a4-c61cd00a6899", true},
+		{"f254df4a184c101980a4c61cd00a6899", true},
+		{"", false},
+		{"not-a-uuid", false},
+		{"<script>", false},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if tc.header != "" {
+			r.Header.Set(DefaultHeader, tc.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if seen == nil || seen.Version() != 1 && !tc.keep {
+			t.Errorf("TestMiddleware(%q): Expecting generated v1 UUID, got %v", tc.header, seen)
+			continue
+		}
+		if tc.keep && seen.String() != "f254df4a-184c-1019-80a4-c61cd00a6899" {
+			t.Errorf("TestMiddleware(%q): Expecting incoming ID, got %s", tc.header, seen)
+		}
+		if act := w.Header().Get(DefaultHeader); act != seen.String() {
+			t.Errorf("TestMiddleware(%q): Expecting response header %s, got %q", tc.header, seen, act)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	m := &RequestID{Header: "X-Correlation-ID", Generate: uuid.NewV7}
+	var seen uuid.UUID
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(DefaultHeader, "f254df4a-184c-1019-80a4-c61cd00a6899")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if seen.Version() != 7 {
+		t.Errorf("TestRequestID: Expecting generated v7 UUID, got %s", seen)
+	}
+	if act := w.Header().Get("X-Correlation-ID"); act != seen.String() {
+		t.Errorf("TestRequestID: Expecting response header %s, got %q", seen, act)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get(DefaultHeader))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &Transport{}}
+
+	get := func(ctx context.Context, header string) string {
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+		if header != "" {
+			req.Header.Set(DefaultHeader, header)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal("TestTransport:", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if req.Header.Get(DefaultHeader) != header {
+			t.Error("TestTransport: the original request was modified")
+		}
+		return string(body)
+	}
+
+	id := uuid.New()
+	ctx := NewContext(context.Background(), id)
+	if act := get(ctx, ""); act != id.String() {
+		t.Errorf("TestTransport: Expecting %s, got %q", id, act)
+	}
+	if act := get(ctx, "explicit"); act != "explicit" {
+		t.Errorf("TestTransport: Expecting explicit header kept, got %q", act)
+	}
+	if act := get(context.Background(), ""); act != "" {
+		t.Errorf("TestTransport: Expecting no header, got %q", act)
+	}
+}
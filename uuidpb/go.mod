@@ -0,0 +1,8 @@
+module github.com/agext/uuid/uuidpb
+
+go 1.23
+
+require (
+	github.com/agext/uuid v1.0.0
+	google.golang.org/protobuf v1.36.12
+)
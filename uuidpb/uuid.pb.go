@@ -0,0 +1,201 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: uuidpb/uuid.proto
+
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuidpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// UUID is a UUID in binary form: at most 18 bytes on the wire, instead of 38 for its canonical string.
+// As proto3 omits zero-valued fields, a zero half takes no bytes, e.g. the nil UUID encodes to 0 bytes.
+type UUID struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// high holds the first 8 bytes of the UUID, in big-endian order.
+	High uint64 `protobuf:"fixed64,1,opt,name=high,proto3" json:"high,omitempty"`
+	// low holds the last 8 bytes of the UUID, in big-endian order.
+	Low           uint64 `protobuf:"fixed64,2,opt,name=low,proto3" json:"low,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UUID) Reset() {
+	*x = UUID{}
+	mi := &file_uuidpb_uuid_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UUID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UUID) ProtoMessage() {}
+
+func (x *UUID) ProtoReflect() protoreflect.Message {
+	mi := &file_uuidpb_uuid_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UUID.ProtoReflect.Descriptor instead.
+func (*UUID) Descriptor() ([]byte, []int) {
+	return file_uuidpb_uuid_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UUID) GetHigh() uint64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *UUID) GetLow() uint64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+// UUIDString is a wrapper for a UUID in canonical string form, for fields that need to stay human-readable,
+// e.g. in JSON payloads. Like the well-known wrapper types, it distinguishes an unset field from an empty value.
+type UUIDString struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// value is the UUID as a dash-separated hex string, e.g. "f254df4a-184c-1019-80a4-c61cd00a6899".
+	Value         string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UUIDString) Reset() {
+	*x = UUIDString{}
+	mi := &file_uuidpb_uuid_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UUIDString) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UUIDString) ProtoMessage() {}
+
+func (x *UUIDString) ProtoReflect() protoreflect.Message {
+	mi := &file_uuidpb_uuid_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UUIDString.ProtoReflect.Descriptor instead.
+func (*UUIDString) Descriptor() ([]byte, []int) {
+	return file_uuidpb_uuid_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UUIDString) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+var File_uuidpb_uuid_proto protoreflect.FileDescriptor
+
+const file_uuidpb_uuid_proto_rawDesc = "" +
+	"\n" +
+	"\x11uuidpb/uuid.proto\x12\n" +
+	"agext.uuid\",\n" +
+	"\x04UUID\x12\x12\n" +
+	"\x04high\x18\x01 \x01(\x06R\x04high\x12\x10\n" +
+	"\x03low\x18\x02 \x01(\x06R\x03low\"\"\n" +
+	"\n" +
+	"UUIDString\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05valueB\x1eZ\x1cgithub.com/agext/uuid/uuidpbb\x06proto3"
+
+var (
+	file_uuidpb_uuid_proto_rawDescOnce sync.Once
+	file_uuidpb_uuid_proto_rawDescData []byte
+)
+
+func file_uuidpb_uuid_proto_rawDescGZIP() []byte {
+	file_uuidpb_uuid_proto_rawDescOnce.Do(func() {
+		file_uuidpb_uuid_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_uuidpb_uuid_proto_rawDesc), len(file_uuidpb_uuid_proto_rawDesc)))
+	})
+	return file_uuidpb_uuid_proto_rawDescData
+}
+
+var file_uuidpb_uuid_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_uuidpb_uuid_proto_goTypes = []any{
+	(*UUID)(nil),       // 0: agext.uuid.UUID
+	(*UUIDString)(nil), // 1: agext.uuid.UUIDString
+}
+var file_uuidpb_uuid_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_uuidpb_uuid_proto_init() }
+func file_uuidpb_uuid_proto_init() {
+	if File_uuidpb_uuid_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_uuidpb_uuid_proto_rawDesc), len(file_uuidpb_uuid_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_uuidpb_uuid_proto_goTypes,
+		DependencyIndexes: file_uuidpb_uuid_proto_depIdxs,
+		MessageInfos:      file_uuidpb_uuid_proto_msgTypes,
+	}.Build()
+	File_uuidpb_uuid_proto = out.File
+	file_uuidpb_uuid_proto_goTypes = nil
+	file_uuidpb_uuid_proto_depIdxs = nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuidpb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/agext/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProto(t *testing.T) {
+	u, _ := uuid.NewFromString("f254df4a-184c-1019-80a4-c61cd00a6899")
+	m, err := ToProto(u)
+	if err != nil || m.High != 0xf254df4a184c1019 || m.Low != 0x80a4c61cd00a6899 {
+		t.Errorf("TestProto: Unexpected message %v (%v)", m, err)
+	}
+
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("TestProto: %v", err)
+	}
+	if len(b) != 18 {
+		t.Errorf("TestProto: Expecting 18 bytes on the wire, got %d", len(b))
+	}
+	var decoded UUID
+	if err = proto.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("TestProto: %v", err)
+	}
+	if u2, err := FromProto(&decoded); err != nil || !bytes.Equal(u, u2) {
+		t.Errorf("TestProto: Expecting %s, got %s (%v)", u, u2, err)
+	}
+
+	// a zero half is omitted on the wire
+	m, _ = ToProto(make(uuid.UUID, 16))
+	if b, _ = proto.Marshal(m); len(b) != 0 {
+		t.Errorf("TestProto: Expecting nil UUID to take 0 bytes on the wire, got %d", len(b))
+	}
+
+	if m, err := ToProto(nil); m != nil || err != nil {
+		t.Errorf("TestProto: Expecting nil message for nil UUID, got %v (%v)", m, err)
+	}
+	if _, err := FromProto(nil); err == nil {
+		t.Errorf("TestProto: Expecting error for nil message")
+	}
+	if m, err := ToProto(uuid.UUID{1, 2}); m != nil || err == nil {
+		t.Errorf("TestProto: Expecting error for invalid UUID length, got %v", m)
+	}
+}
+
+func TestStringProto(t *testing.T) {
+	u := uuid.New()
+	m, err := ToStringProto(u)
+	if err != nil || m.Value != u.String() {
+		t.Errorf("TestStringProto: Expecting %s, got %v (%v)", u, m, err)
+	}
+	if u2, err := FromStringProto(m); err != nil || !bytes.Equal(u, u2) {
+		t.Errorf("TestStringProto: Expecting %s, got %s (%v)", u, u2, err)
+	}
+	if u2, err := FromStringProto(&UUIDString{Value: "F254DF4A-184C-1019-80A4-C61CD00A6899"}); err != nil || u2.String() != "f254df4a-184c-1019-80a4-c61cd00a6899" {
+		t.Errorf("TestStringProto: Expecting upper case UUID to be accepted, got %s (%v)", u2, err)
+	}
+
+	for _, value := range []string{"", "f254df4a184c101980a4c61cd00a6899", "f254df4a-184c-1019-80a4-c61cd00a689", "f254df4a-184c-1019-80a4c-61cd00a6899", "g254df4a-184c-1019-80a4-c61cd00a6899"} {
+		if _, err := FromStringProto(&UUIDString{Value: value}); err == nil {
+			t.Errorf("TestStringProto: Expecting error for %q", value)
+		}
+	}
+	if _, err := FromStringProto(nil); err == nil {
+		t.Errorf("TestStringProto: Expecting error for nil message")
+	}
+	if m, err := ToStringProto(nil); m != nil || err != nil {
+		t.Errorf("TestStringProto: Expecting nil message for nil UUID, got %v (%v)", m, err)
+	}
+	if m, err := ToStringProto(uuid.UUID{1, 2}); m != nil || err == nil {
+		t.Errorf("TestStringProto: Expecting error for invalid UUID length, got %v", m)
+	}
+}
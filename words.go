@@ -0,0 +1,292 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ProquintEncoder renders UUIDs as pronounceable quintuplets (see https://arxiv.org/html/0901.4016)
+	ProquintEncoder = ProquintEncoding{}
+)
+
+const (
+	proquintConsonants = "bdfghjklmnprstvz"
+	proquintVowels     = "aiou"
+)
+
+// corrections of the letters outside the proquint alphabets to the nearest-sounding consonant or vowel
+var (
+	proquintConsonantFixes = map[byte]byte{'c': 'k', 'q': 'k', 'w': 'v', 'x': 'z'}
+	proquintVowelFixes     = map[byte]byte{'e': 'i', 'y': 'i'}
+)
+
+// ProquintEncoding implements the proquint encoding to satisfy EncoderToString and DecoderFromString.
+//
+// Every 16 bits are rendered as a consonant-vowel-consonant-vowel-consonant word, and the words are
+// separated by dashes, e.g. "lusab-babad-gutih-tugad-...". An odd trailing byte is padded with zero.
+type ProquintEncoding struct{}
+
+// EncodeToString encodes the source to a string of dash-separated proquints.
+func (ProquintEncoding) EncodeToString(src []byte) string {
+	var sb strings.Builder
+	for i := 0; i < len(src); i += 2 {
+		val := uint16(src[i]) << 8
+		if i+1 < len(src) {
+			val |= uint16(src[i+1])
+		}
+		if i > 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(proquintConsonants[val>>12])
+		sb.WriteByte(proquintVowels[(val>>10)&0x03])
+		sb.WriteByte(proquintConsonants[(val>>6)&0x0f])
+		sb.WriteByte(proquintVowels[(val>>4)&0x03])
+		sb.WriteByte(proquintConsonants[val&0x0f])
+	}
+	return sb.String()
+}
+
+// DecodeString decodes a string of proquints.
+//
+// Decoding is case-insensitive and typo-tolerant: the words may be separated by dashes, white space,
+// or nothing at all, and a letter that does not belong to the alphabet of its position is corrected to
+// the nearest-sounding consonant (c or q to k, w to v, x to z) or vowel (e or y to i).
+// Any other letter, and any character other than letters, dashes and white space, is rejected.
+func (ProquintEncoding) DecodeString(src string) ([]byte, error) {
+	letters := make([]byte, 0, len(src))
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		switch {
+		case c >= 'a' && c <= 'z':
+			letters = append(letters, c)
+		case c == '-' || c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			return nil, fmt.Errorf("uuid.ProquintEncoding.DecodeString: invalid character %q in %s", c, src)
+		}
+	}
+	if len(letters)%5 != 0 {
+		return nil, fmt.Errorf("uuid.ProquintEncoding.DecodeString: %s is not a sequence of proquints", src)
+	}
+	out := make([]byte, 0, len(letters)/5*2)
+	for i := 0; i < len(letters); i += 5 {
+		var val uint16
+		for j, c := range letters[i : i+5] {
+			var idx int
+			if j%2 == 0 {
+				if fix, ok := proquintConsonantFixes[c]; ok {
+					c = fix
+				}
+				idx = strings.IndexByte(proquintConsonants, c)
+				val <<= 4
+			} else {
+				if fix, ok := proquintVowelFixes[c]; ok {
+					c = fix
+				}
+				idx = strings.IndexByte(proquintVowels, c)
+				val <<= 2
+			}
+			if idx < 0 {
+				return nil, fmt.Errorf("uuid.ProquintEncoding.DecodeString: invalid proquint %s", letters[i:i+5])
+			}
+			val |= uint16(idx)
+		}
+		out = append(out, byte(val>>8), byte(val))
+	}
+	return out, nil
+}
+
+// WordListEncoding implements a BIP-39 style mnemonic encoding over a configurable list of 2048 words,
+// to satisfy EncoderToString and DecoderFromString.
+//
+// The source is extended with a checksum of one bit per 32 bits of input, taken from the start of its
+// SHA-256 hash, and the result is split into 11-bit indexes into the word list. A UUID is thus rendered
+// as 12 space-separated words. The source length should be a multiple of 4 bytes (as is the case for a UUID),
+// otherwise it is padded with zero bytes.
+type WordListEncoding struct {
+	words  []string
+	index  map[string]int
+	prefix map[string]int
+}
+
+const (
+	wordListSize   = 2048
+	wordBits       = 11
+	wordPrefixSize = 4
+)
+
+// NewWordListEncoding creates a WordListEncoding using the provided list of exactly 2048 distinct words,
+// such as one of the BIP-39 word lists.
+func NewWordListEncoding(words []string) (*WordListEncoding, error) {
+	if len(words) != wordListSize {
+		return nil, fmt.Errorf("uuid.NewWordListEncoding: word list length is wrong (%d instead of %d)", len(words), wordListSize)
+	}
+	e := &WordListEncoding{
+		words:  make([]string, len(words)),
+		index:  make(map[string]int, len(words)),
+		prefix: make(map[string]int, len(words)),
+	}
+	for i, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w == "" || strings.ContainsAny(w, " \t\r\n-") {
+			return nil, fmt.Errorf("uuid.NewWordListEncoding: invalid word %q at index %d", words[i], i)
+		}
+		if _, ok := e.index[w]; ok {
+			return nil, fmt.Errorf("uuid.NewWordListEncoding: duplicate word %q at index %d", words[i], i)
+		}
+		e.words[i] = w
+		e.index[w] = i
+		if len(w) >= wordPrefixSize {
+			p := w[:wordPrefixSize]
+			if _, ok := e.prefix[p]; ok {
+				// ambiguous prefix, not usable for matching abbreviated words
+				e.prefix[p] = -1
+			} else {
+				e.prefix[p] = i
+			}
+		}
+	}
+	return e, nil
+}
+
+// EncodeToString encodes the source to a string of space-separated words.
+func (e *WordListEncoding) EncodeToString(src []byte) string {
+	if n := len(src) % 4; n != 0 {
+		src = append(append([]byte{}, src...), make([]byte, 4-n)...)
+	}
+	bits := append(append([]byte{}, src...), wordListChecksum(src)...)
+	total := len(src)*8 + len(src)/4
+	var sb strings.Builder
+	for pos := 0; pos < total; pos += wordBits {
+		if pos > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(e.words[readBits(bits, pos, wordBits)])
+	}
+	return sb.String()
+}
+
+// DecodeString decodes a string of words separated by white space or dashes.
+//
+// Matching is typo-tolerant: words are case-insensitive, may be abbreviated to a unique prefix
+// of at least 4 letters, and a word that is one edit (insertion, deletion, substitution or transposition
+// of adjacent letters) away from exactly one word in the list is corrected. The checksum is always verified,
+// and ErrChecksum is returned on mismatch.
+func (e *WordListEncoding) DecodeString(src string) ([]byte, error) {
+	fields := strings.FieldsFunc(src, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '-'
+	})
+	if len(fields) == 0 || len(fields)%3 != 0 {
+		return nil, fmt.Errorf("uuid.WordListEncoding.DecodeString: word count is wrong (%d is not a multiple of 3)", len(fields))
+	}
+	n := len(fields) * wordBits * 32 / 33 / 8
+	bits := make([]byte, n+(n/4+7)/8)
+	for i, f := range fields {
+		idx, err := e.match(f)
+		if err != nil {
+			return nil, err
+		}
+		writeBits(bits, i*wordBits, wordBits, idx)
+	}
+	out, sum := bits[:n], bits[n:]
+	exp := wordListChecksum(out)
+	cs := n / 4
+	if readBits(sum, 0, cs) != readBits(exp, 0, cs) {
+		return nil, ErrChecksum
+	}
+	return out, nil
+}
+
+// match finds the index of the word in the list that best matches the provided one.
+func (e *WordListEncoding) match(word string) (int, error) {
+	w := strings.ToLower(word)
+	if i, ok := e.index[w]; ok {
+		return i, nil
+	}
+	if len(w) >= wordPrefixSize {
+		if i, ok := e.prefix[w[:wordPrefixSize]]; ok && i >= 0 && strings.HasPrefix(e.words[i], w) {
+			return i, nil
+		}
+	}
+	found := -1
+	for i, c := range e.words {
+		if editDistanceOne(w, c) {
+			if found >= 0 {
+				return 0, fmt.Errorf("uuid.WordListEncoding.DecodeString: ambiguous word %q (%s or %s)", word, e.words[found], c)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("uuid.WordListEncoding.DecodeString: unknown word %q", word)
+	}
+	return found, nil
+}
+
+// wordListChecksum returns the SHA-256 hash of the source, whose leading bits are used as checksum.
+func wordListChecksum(src []byte) []byte {
+	sum := sha256.Sum256(src)
+	return sum[:]
+}
+
+// readBits returns the n-bit big-endian value starting at bit offset pos of b.
+func readBits(b []byte, pos, n int) int {
+	val := 0
+	for i := pos; i < pos+n; i++ {
+		val = val<<1 | int(b[i/8]>>(7-uint(i%8))&1)
+	}
+	return val
+}
+
+// writeBits stores the n-bit big-endian value val starting at bit offset pos of b.
+func writeBits(b []byte, pos, n, val int) {
+	for i := 0; i < n; i++ {
+		if val>>(uint(n-1-i))&1 != 0 {
+			b[(pos+i)/8] |= 1 << (7 - uint((pos+i)%8))
+		}
+	}
+}
+
+// editDistanceOne reports whether a can be turned into b by exactly one insertion, deletion,
+// substitution, or transposition of adjacent characters.
+func editDistanceOne(a, b string) bool {
+	la, lb := len(a), len(b)
+	if la > lb {
+		a, b, la, lb = b, a, lb, la
+	}
+	if lb-la > 1 {
+		return false
+	}
+	i := 0
+	for i < la && a[i] == b[i] {
+		i++
+	}
+	if la == lb {
+		if i == la {
+			return false
+		}
+		if a[i+1:] == b[i+1:] {
+			return true
+		}
+		return i+1 < la && a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+	}
+	return a[i:] == b[i+1:]
+}
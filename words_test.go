@@ -0,0 +1,186 @@
+// Copyright 2015 ALRUX Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uuid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestProquint(t *testing.T) {
+	// test vectors from the proquint specification
+	tcs := []struct {
+		src []byte
+		out string
+	}{
+		{[]byte{127, 0, 0, 1}, "lusab-babad"},
+		{[]byte{63, 84, 220, 193}, "gutih-tugad"},
+		{[]byte{255, 255, 255, 255}, "zuzuz-zuzuz"},
+		{[]byte{0, 0, 0, 0}, "babab-babab"},
+	}
+	for _, tc := range tcs {
+		if act := ProquintEncoder.EncodeToString(tc.src); act != tc.out {
+			t.Errorf("TestProquint: got %s want %s", act, tc.out)
+		}
+		act, err := ProquintEncoder.DecodeString(tc.out)
+		if err != nil {
+			t.Errorf("TestProquint(%s): %s", tc.out, err)
+		}
+		if !bytes.Equal(act, tc.src) {
+			t.Errorf("TestProquint(%s): got % x want % x", tc.out, act, tc.src)
+		}
+	}
+
+	uuid1, _ := NewFromBytes(uuid)
+	enc := uuid1.EncodeToString(ProquintEncoder)
+	for _, s := range []string{enc, strings.ToUpper(enc), strings.Replace(enc, "-", " ", -1), strings.Replace(enc, "-", "", -1)} {
+		uuid2, err := NewFromEncodedString(ProquintEncoder, s)
+		if err != nil {
+			t.Errorf("TestProquint(%s): %s", s, err)
+		} else if uuid2.String() != uuidString {
+			t.Errorf("TestProquint(%s): got %s want %s", s, uuid2, uuidString)
+		}
+	}
+
+	if _, err := ProquintEncoder.DecodeString("lusab-baba"); err == nil {
+		t.Error("TestProquint: should fail on short proquint")
+	}
+	if _, err := ProquintEncoder.DecodeString("lusab-aabad"); err == nil {
+		t.Error("TestProquint: should fail on invalid letter")
+	}
+
+	// letter typos are corrected to the nearest consonant or vowel
+	for typo, fixed := range map[string]string{
+		"lusab-bebad": "lusab-bibad",
+		"LUSAQ BABAD": "lusak-babad",
+		"lysab-babad": "lisab-babad",
+		"cusaw-xabad": "kusav-zabad",
+	} {
+		want, _ := ProquintEncoder.DecodeString(fixed)
+		if act, err := ProquintEncoder.DecodeString(typo); err != nil || !bytes.Equal(act, want) {
+			t.Errorf("TestProquint(%s): got % x, %v want % x", typo, act, err, want)
+		}
+	}
+	// other characters are rejected rather than dropped
+	for _, s := range []string{"lusab-ba1bad", "lusab-bab.ad", "lusab_babad", "lus4b-babad"} {
+		if act, err := ProquintEncoder.DecodeString(s); err == nil {
+			t.Errorf("TestProquint(%s): should fail on invalid character, got % x", s, act)
+		}
+	}
+}
+
+// testWords returns a list of 2048 words with unique 4-letter prefixes, sparse enough
+// that no two words are within edit distance one.
+func testWords() []string {
+	const cons, vows = proquintConsonants, proquintVowels
+	words := make([]string, wordListSize)
+	for i := range words {
+		sum := sha256.Sum256([]byte{byte(i >> 8), byte(i)})
+		words[i] = string([]byte{cons[i>>7&15], vows[i>>5&3], cons[i>>1&15], vows[i&1]}) +
+			ProquintEncoder.EncodeToString(sum[:2])
+	}
+	return words
+}
+
+func TestWordList(t *testing.T) {
+	if _, err := NewWordListEncoding(testWords()[1:]); err == nil {
+		t.Error("TestWordList: should fail on short word list")
+	}
+	words := testWords()
+	words[7] = words[3]
+	if _, err := NewWordListEncoding(words); err == nil {
+		t.Error("TestWordList: should fail on duplicate words")
+	}
+	words[7] = "two words"
+	if _, err := NewWordListEncoding(words); err == nil {
+		t.Error("TestWordList: should fail on invalid words")
+	}
+
+	enc, err := NewWordListEncoding(testWords())
+	if err != nil {
+		t.Fatal("TestWordList:", err)
+	}
+	for i, tc := range encTCs {
+		uuid1, _ := NewFromString(tc.src)
+		s := uuid1.EncodeToString(enc)
+		fields := strings.Fields(s)
+		if len(fields) != 12 {
+			t.Errorf("TestWordList[%d]: expecting 12 words, got %d", i, len(fields))
+		}
+		uuid2, err := NewFromEncodedString(enc, s)
+		if err != nil {
+			t.Errorf("TestWordList[%d]: %s", i, err)
+		} else if uuid2.String() != tc.src {
+			t.Errorf("TestWordList[%d]: got %s want %s", i, uuid2, tc.src)
+		}
+
+		// typos: substitution, transposition, deletion, insertion, abbreviation, upper case
+		w := fields[0]
+		fields[0] = w[:len(w)-1] + string(w[len(w)-1]^0x01)
+		w = fields[1]
+		fields[1] = w[:5] + string(w[6]) + string(w[5]) + w[7:]
+		w = fields[2]
+		fields[2] = w[:6] + w[7:]
+		w = fields[3]
+		fields[3] = w[:6] + "x" + w[6:]
+		fields[4] = fields[4][:4]
+		fields[5] = strings.ToUpper(fields[5])
+		uuid2, err = NewFromEncodedString(enc, strings.Join(fields, "-"))
+		if err != nil {
+			t.Errorf("TestWordList[%d] with typos: %s", i, err)
+		} else if uuid2.String() != tc.src {
+			t.Errorf("TestWordList[%d] with typos: got %s want %s", i, uuid2, tc.src)
+		}
+	}
+
+	uuid1, _ := NewFromBytes(uuid)
+	fields := strings.Fields(uuid1.EncodeToString(enc))
+	fields[0], fields[1] = fields[1], fields[0]
+	if _, err = enc.DecodeString(strings.Join(fields, " ")); err != ErrChecksum {
+		t.Errorf("TestWordList: expecting ErrChecksum, got %v", err)
+	}
+	if _, err = enc.DecodeString(strings.Join(fields[1:], " ")); err == nil {
+		t.Error("TestWordList: should fail on wrong word count")
+	}
+	fields[2] = "nonsense"
+	if _, err = enc.DecodeString(strings.Join(fields, " ")); err == nil {
+		t.Error("TestWordList: should fail on unknown word")
+	}
+}
+
+func TestEditDistanceOne(t *testing.T) {
+	tcs := []struct {
+		a, b string
+		exp  bool
+	}{
+		{"abcd", "abcd", false},
+		{"abcd", "abed", true},
+		{"abcd", "abdc", true},
+		{"abcd", "bacd", true},
+		{"abcd", "abc", true},
+		{"abcd", "bcd", true},
+		{"abcd", "abxcd", true},
+		{"abcd", "badc", false},
+		{"abcd", "ab", false},
+		{"abcd", "dcba", false},
+	}
+	for _, tc := range tcs {
+		if act := editDistanceOne(tc.a, tc.b); act != tc.exp {
+			t.Errorf("TestEditDistanceOne(%s, %s): got %v want %v", tc.a, tc.b, act, tc.exp)
+		}
+	}
+}